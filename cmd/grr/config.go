@@ -24,6 +24,9 @@ type Opts struct {
 	Targets      []string
 	OutputFormat string
 	DisableStats bool
+	EventsFormat string
+	EventsOutput string
+	Report       string
 	IsDir        bool // used internally to denote that the resource path argument pointed at a directory
 
 	// Used for supporting resources without envelopes
@@ -78,11 +81,7 @@ func currentContextCmd() *cli.Command {
 	var opts LoggingOpts
 
 	cmd.Run = func(cmd *cli.Command, args []string) error {
-		conf, err := config.CurrentContext()
-		if err != nil {
-			return err
-		}
-		fmt.Println(conf.Name)
+		fmt.Println(config.CurrentContextName())
 		return nil
 	}
 	return initialiseLogging(cmd, &opts)
@@ -116,13 +115,10 @@ func getContextsCmd() *cli.Command {
 		if err != nil {
 			return err
 		}
-		currentContext, err := config.CurrentContext()
-		if err != nil {
-			return err
-		}
+		currentContext := config.CurrentContextName()
 
 		for _, context := range contexts {
-			if context == currentContext.Name {
+			if context == currentContext {
 				fmt.Printf("* %s\n", context)
 			} else {
 				fmt.Printf("  %s\n", context)
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-clix/cli"
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/grafana/grizzly/pkg/mimir"
+	log "github.com/sirupsen/logrus"
+)
+
+// ConvertOpts contains the options of the convert command
+type ConvertOpts struct {
+	Opts
+	From          string
+	To            string
+	DatasourceUID string
+	FolderUID     string
+}
+
+// converter converts a resource into a resource of another kind, handled by
+// handler, along with warnings about what couldn't be converted.
+type converter func(handler grizzly.Handler, resource grizzly.Resource, opts ConvertOpts) (*grizzly.Resource, []string, error)
+
+func convertPrometheusRuleGroup(handler grizzly.Handler, resource grizzly.Resource, opts ConvertOpts) (*grizzly.Resource, []string, error) {
+	if opts.DatasourceUID == "" || opts.FolderUID == "" {
+		return nil, nil, fmt.Errorf("--datasource and --folder are required to convert %s resources", mimir.PrometheusRuleGroupKind)
+	}
+	return handler.(*grafana.AlertRuleGroupHandler).ConvertPrometheusRuleGroup(resource, grafana.PrometheusConversionOptions{
+		DatasourceUID: opts.DatasourceUID,
+		FolderUID:     opts.FolderUID,
+	})
+}
+
+// converters lists the supported conversions, by source and target kinds
+var converters = map[string]map[string]converter{
+	mimir.PrometheusRuleGroupKind: {
+		grafana.AlertRuleGroupKind: convertPrometheusRuleGroup,
+	},
+}
+
+func convertCmd(registry grizzly.Registry) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "convert <resource-path> [<output-dir>]",
+		Short: "Convert resources to another kind, printing them or writing them to a directory",
+		Args:  cli.ArgsRange(1, 2),
+	}
+	var opts ConvertOpts
+
+	cmd.Flags().StringVar(&opts.From, "from", "", "kind of the resources to convert")
+	cmd.Flags().StringVar(&opts.To, "to", "", "kind to convert resources to")
+	cmd.Flags().StringVar(&opts.DatasourceUID, "datasource", "", "UID of the datasource converted alert rules query")
+	cmd.Flags().StringVar(&opts.FolderUID, "folder", "", "UID of the folder converted alert rules are stored in")
+
+	cmd.Run = func(cmd *cli.Command, args []string) error {
+		convert, ok := converters[opts.From][opts.To]
+		if !ok {
+			return fmt.Errorf("can't convert '%s' resources to '%s', supported conversions: %s", opts.From, opts.To, supportedConversions())
+		}
+
+		currentContext, err := config.CurrentContext()
+		if err != nil {
+			return err
+		}
+		targets := currentContext.GetTargets(opts.Targets)
+
+		resources, err := newParser(registry, currentContext, targets, opts.Opts).Parse(args[0], grizzly.ParserOptions{})
+		if err != nil {
+			return err
+		}
+
+		handler, err := registry.GetHandler(opts.To)
+		if err != nil {
+			return err
+		}
+
+		format, onlySpec, err := getOutputFormat(opts.Opts)
+		if err != nil {
+			return err
+		}
+
+		converted := map[string]string{}
+		for _, resource := range resources.OfKind(opts.From).AsList() {
+			result, warnings, err := convert(handler, resource, opts)
+			if err != nil {
+				return err
+			}
+			// warnings are logged, to keep them out of printed resources
+			for _, warning := range warnings {
+				log.Warnf("%s: %s", resource.Ref(), warning)
+			}
+
+			if err := handler.Validate(*result); err != nil {
+				return err
+			}
+			if source, found := converted[result.Name()]; found {
+				return fmt.Errorf("%s and %s both convert to %s", source, resource.Ref(), result.Ref())
+			}
+			converted[result.Name()] = resource.Ref().String()
+
+			outputDir := ""
+			if len(args) == 2 {
+				outputDir = args[1]
+			}
+			content, filename, _, err := grizzly.Format(registry, outputDir, result, format, onlySpec)
+			if err != nil {
+				return err
+			}
+
+			if outputDir == "" {
+				fmt.Fprintf(os.Stdout, "---\n%s", content)
+				continue
+			}
+			if err := grizzly.WriteFile(filename, content); err != nil {
+				return err
+			}
+			notifier.Info(resource.Ref(), "converted to "+filename)
+		}
+		return nil
+	}
+	return initialiseCmd(cmd, &opts.Opts)
+}
+
+func supportedConversions() string {
+	var conversions []string
+	for from, targets := range converters {
+		for to := range targets {
+			conversions = append(conversions, fmt.Sprintf("%s to %s", from, to))
+		}
+	}
+	return strings.Join(conversions, ", ")
+}
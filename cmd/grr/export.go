@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+	"github.com/grafana/grizzly/pkg/mimir"
+)
+
+// exportFormatGrizzly exports resources as grizzly resources files
+const exportFormatGrizzly = "grizzly"
+
+// ExportOpts contains the options of the export command
+type ExportOpts struct {
+	Opts
+	Format           string
+	Namespace        string
+	InstanceSelector string
+	ResolveSecrets   bool
+}
+
+// exporter translates resources into files of another tool, by path relative
+// to the export directory, along with warnings about what couldn't be
+// translated.
+type exporter func(resources grizzly.Resources, opts ExportOpts) (map[string][]byte, []string, error)
+
+func exportAlertmanager(resources grizzly.Resources, opts ExportOpts) (map[string][]byte, []string, error) {
+	return grafana.ExportAlertmanagerConfig(resources, grafana.AlertmanagerOptions{ResolveSecrets: opts.ResolveSecrets})
+}
+
+func exportGrafanaProvisioning(resources grizzly.Resources, _ ExportOpts) (map[string][]byte, []string, error) {
+	return grafana.ExportProvisioningFiles(resources)
+}
+
+func exportGrafanaOperator(resources grizzly.Resources, opts ExportOpts) (map[string][]byte, []string, error) {
+	instanceSelector, err := parseLabels(opts.InstanceSelector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --instance-selector: %w", err)
+	}
+
+	files, warnings, err := grafana.ExportOperatorManifests(resources, grafana.OperatorOptions{
+		Namespace:        opts.Namespace,
+		InstanceSelector: instanceSelector,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules, rulesWarnings, err := mimir.ExportPrometheusRules(resources, opts.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	for path, content := range rules {
+		files[path] = content
+	}
+	warnings = append(warnings, rulesWarnings...)
+
+	exported := map[string]bool{mimir.PrometheusRuleGroupKind: true}
+	for _, kind := range grafana.OperatorKinds {
+		exported[kind] = true
+	}
+	byKind := resources.GroupByKind()
+	var skipped []string
+	for kind := range byKind {
+		if !exported[kind] {
+			skipped = append(skipped, kind)
+		}
+	}
+	sort.Strings(skipped)
+	for _, kind := range skipped {
+		warnings = append(warnings, fmt.Sprintf("%d %s resources have no grafana-operator equivalent, skipped", byKind[kind].Len(), kind))
+	}
+	return files, warnings, nil
+}
+
+// parseLabels parses labels written as key=value pairs separated by commas
+func parseLabels(text string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(text, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("expected key=value, got '%s'", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// exporters lists the supported export formats, besides grizzly resources
+var exporters = map[string]exporter{
+	"alertmanager":         exportAlertmanager,
+	"grafana-provisioning": exportGrafanaProvisioning,
+	"grafana-operator":     exportGrafanaOperator,
+}
+
+// exportAs writes resources to exportDir in the given format
+func exportAs(format string, exportDir string, resources grizzly.Resources, opts ExportOpts) error {
+	export, ok := exporters[format]
+	if !ok {
+		return fmt.Errorf("unknown export format '%s', expected one of %s", format, strings.Join(exportFormats(), ", "))
+	}
+	if opts.ResolveSecrets && format != "alertmanager" {
+		return fmt.Errorf("--resolve-secrets is only supported with the alertmanager format")
+	}
+
+	files, warnings, err := export(resources, opts)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		notifier.Warn(nil, warning)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		filename := filepath.Join(exportDir, filepath.FromSlash(path))
+		if err := writeExportedFile(filename, files[path], opts.ResolveSecrets); err != nil {
+			return err
+		}
+		notifier.Info(nil, "exported "+filename)
+	}
+	return nil
+}
+
+// writeExportedFile writes an exported file, only readable by its owner when
+// it may hold secrets
+func writeExportedFile(filename string, content []byte, private bool) error {
+	if !private {
+		return grizzly.WriteFile(filename, content)
+	}
+	return writePrivateFile(filename, content)
+}
+
+// writePrivateFile writes a file only readable by its owner, restricting the
+// mode of an existing file before its content is replaced.
+func writePrivateFile(filename string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	// the mode of WriteFile only applies to new files
+	if err := os.Chmod(filename, 0600); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(filename, content, 0600)
+}
+
+func exportFormats() []string {
+	formats := []string{exportFormatGrizzly}
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats[1:])
+	return formats
+}
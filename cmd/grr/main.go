@@ -45,12 +45,20 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	// A context that can't be interpolated must not prevent the config
+	// commands from fixing it: other commands read the context again and
+	// report the error.
 	context, err := config.CurrentContext()
 	if err != nil {
-		log.Fatalln(err)
+		log.Debugf("Reading current context: %s", err)
+		context = &config.Context{Name: config.CurrentContextName()}
 	}
 
-	log.AddHook(logger.NewSecretsRedactor(context.Secrets()))
+	// Credentials resolved by providers are stored in the context, which
+	// must not be copied for them to be redacted as well.
+	log.AddHook(logger.NewSecretsRedactor(func() []string {
+		return context.Secrets()
+	}))
 
 	registry := createRegistry(context)
 	// workflow commands
@@ -61,12 +69,16 @@ func main() {
 		showCmd(registry),
 		diffCmd(registry),
 		applyCmd(registry),
+		deleteCmd(registry),
 		watchCmd(registry),
 		exportCmd(registry),
 		snapshotCmd(registry),
 		providersCmd(registry),
 		configCmd(registry),
 		serveCmd(registry),
+		serviceAccountCmd(registry),
+		renderTemplateCmd(registry),
+		convertCmd(registry),
 		selfUpdateCmd(),
 	)
 
@@ -83,9 +95,9 @@ func main() {
 
 func createRegistry(context *config.Context) grizzly.Registry {
 	providers := []grizzly.Provider{
-		grafana.NewProvider(&context.Grafana),
-		mimir.NewProvider(&context.Mimir),
-		syntheticmonitoring.NewProvider(&context.SyntheticMonitoring),
+		grafana.NewProvider(&context.Grafana, &context.HTTP),
+		mimir.NewProvider(&context.Mimir, &context.HTTP),
+		syntheticmonitoring.NewProvider(&context.SyntheticMonitoring, &context.HTTP),
 	}
 
 	return grizzly.NewRegistry(providers)
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-clix/cli"
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/grafana/grizzly/pkg/grizzly/notifier"
+)
+
+func renderTemplateCmd(registry grizzly.Registry) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "render-template <template-resource>",
+		Short: "Render notification templates locally against sample alerts",
+		Args:  cli.ArgsExact(1),
+	}
+	var opts Opts
+	var data string
+	var templateNames []string
+
+	cmd.Flags().StringVar(&data, "data", "firing", fmt.Sprintf("notification to render templates with: a JSON file, or one of the samples %s", strings.Join(grafana.NotificationSamples, ", ")))
+	cmd.Flags().StringSliceVar(&templateNames, "template", nil, "names of the templates to render. Default: every template defined by the resources")
+
+	cmd.Run = func(cmd *cli.Command, args []string) error {
+		currentContext, err := config.CurrentContext()
+		if err != nil {
+			return err
+		}
+		targets := currentContext.GetTargets(opts.Targets)
+
+		resources, err := newParser(registry, currentContext, targets, opts).Parse(args[0], grizzly.ParserOptions{})
+		if err != nil {
+			return err
+		}
+
+		templates := resources.OfKind(grafana.KindAlertNotificationTemplate).AsList()
+		if len(templates) == 0 {
+			return fmt.Errorf("no %s resources found in %s", grafana.KindAlertNotificationTemplate, args[0])
+		}
+
+		notification, err := loadNotificationData(data)
+		if err != nil {
+			return err
+		}
+
+		rendered, err := grafana.RenderNotificationTemplates(templates, templateNames, notification)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, template := range rendered {
+			if template.Err != nil {
+				failed++
+				notifier.Error(notifier.SimpleString(template.Name), template.Err.Error())
+				continue
+			}
+			fmt.Printf("--- %s ---\n%s\n", template.Name, template.Output)
+		}
+
+		// errors are already displayed, so we return a "silent" one to
+		// ensure that the exit code will be non-zero
+		if failed != 0 {
+			return silentError{Err: fmt.Errorf("%d templates failed to render", failed)}
+		}
+		return nil
+	}
+	return initialiseCmd(cmd, &opts)
+}
+
+// loadNotificationData reads a sample notification from a JSON file, or from
+// the built-in samples.
+func loadNotificationData(data string) (grafana.NotificationData, error) {
+	if slices.Contains(grafana.NotificationSamples, data) {
+		return grafana.LoadNotificationSample(data)
+	}
+
+	content, err := os.ReadFile(data)
+	if err != nil {
+		return grafana.NotificationData{}, err
+	}
+	return grafana.ParseNotificationData(content)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/go-clix/cli"
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grafana"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// TokenOpts contains the options of service account token commands
+type TokenOpts struct {
+	LoggingOpts
+	Name         string
+	TTL          time.Duration
+	OutputFile   string
+	StoreCommand []string
+}
+
+func serviceAccountCmd(registry grizzly.Registry) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "service-account <sub-command>",
+		Short: "Manage the tokens of Grafana service accounts",
+		Args:  cli.ArgsExact(0),
+	}
+	tokenCmd := &cli.Command{
+		Use:   "token <sub-command>",
+		Short: "Create or rotate service account tokens",
+		Args:  cli.ArgsExact(0),
+	}
+	tokenCmd.AddCommand(tokenCreateCmd(registry))
+	tokenCmd.AddCommand(tokenRotateCmd(registry))
+	cmd.AddCommand(tokenCmd)
+	return cmd
+}
+
+func tokenCreateCmd(registry grizzly.Registry) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "create <service-account>",
+		Short: "Create a token for a service account",
+		Args:  cli.ArgsExact(1),
+	}
+	var opts TokenOpts
+
+	cmd.Run = func(cmd *cli.Command, args []string) error {
+		handler, err := serviceAccountHandler(registry, opts)
+		if err != nil {
+			return err
+		}
+
+		token, err := handler.CreateToken(args[0], opts.Name, opts.TTL)
+		if err != nil {
+			return err
+		}
+		return storeToken(token, opts)
+	}
+	return initialiseToken(cmd, &opts)
+}
+
+func tokenRotateCmd(registry grizzly.Registry) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "rotate <service-account>",
+		Short: "Replace the tokens of a service account with a new one",
+		Args:  cli.ArgsExact(1),
+	}
+	var opts TokenOpts
+
+	cmd.Run = func(cmd *cli.Command, args []string) error {
+		handler, err := serviceAccountHandler(registry, opts)
+		if err != nil {
+			return err
+		}
+
+		return handler.RotateToken(args[0], opts.Name, opts.TTL, func(token string) error {
+			return storeToken(token, opts)
+		})
+	}
+	return initialiseToken(cmd, &opts)
+}
+
+func initialiseToken(cmd *cli.Command, opts *TokenOpts) *cli.Command {
+	cmd.Flags().StringVar(&opts.Name, "name", "grizzly", "name of the token")
+	cmd.Flags().DurationVar(&opts.TTL, "ttl", 0, "lifetime of the token, e.g. 720h. Default 0 (never expires)")
+	cmd.Flags().StringVar(&opts.OutputFile, "output-file", "", "file to write the token to, or - for the standard output")
+	cmd.Flags().StringSliceVar(&opts.StoreCommand, "store-command", nil, "credential helper command to send the token to, on its standard input")
+	return initialiseLogging(cmd, &opts.LoggingOpts)
+}
+
+func serviceAccountHandler(registry grizzly.Registry, opts TokenOpts) (*grafana.ServiceAccountHandler, error) {
+	if (opts.OutputFile == "") == (len(opts.StoreCommand) == 0) {
+		return nil, fmt.Errorf("one of --output-file or --store-command is required")
+	}
+	if _, err := config.CurrentContext(); err != nil {
+		return nil, err
+	}
+
+	handler, err := registry.GetHandler(grafana.KindServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	return handler.(*grafana.ServiceAccountHandler), nil
+}
+
+// storeToken writes a token to the output file or credential helper of opts.
+func storeToken(token string, opts TokenOpts) error {
+	switch {
+	case opts.OutputFile == "-":
+		fmt.Println(token)
+		return nil
+
+	case opts.OutputFile != "":
+		return writePrivateFile(opts.OutputFile, []byte(token+"\n"))
+
+	default:
+		cmd := exec.Command(opts.StoreCommand[0], opts.StoreCommand[1:]...)
+		cmd.Stdin = strings.NewReader(token + "\n")
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("running credential helper: %w", err)
+		}
+		return nil
+	}
+}
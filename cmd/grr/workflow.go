@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/go-clix/cli"
@@ -17,6 +20,13 @@ import (
 
 const generalFolderUID = "general"
 
+const (
+	eventsFormatText   = "text"
+	eventsFormatJSON   = "json"
+	eventsFormatJUnit  = "junit"
+	eventsFormatGitHub = "github"
+)
+
 func getCmd(registry grizzly.Registry) *cli.Command {
 	cmd := &cli.Command{
 		Use:   "get <resource-type>.<resource-uid>",
@@ -64,7 +74,11 @@ func listCmd(registry grizzly.Registry) *cli.Command {
 
 			return grizzly.ListRemote(registry, targets, format)
 		}
-		if len(args) == 0 {
+		resourcePath := config.CurrentProject().ResolvedResourcePath()
+		if len(args) > 0 {
+			resourcePath = args[0]
+		}
+		if resourcePath == "" {
 			notifier.Error(nil, "resource-path required when listing local resources")
 			return nil
 		}
@@ -74,7 +88,7 @@ func listCmd(registry grizzly.Registry) *cli.Command {
 			return err
 		}
 
-		resources, err := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths).Parse(args[0], grizzly.ParserOptions{
+		resources, err := newParser(registry, currentContext, targets, opts).Parse(resourcePath, grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
 			DefaultFolderUID:    folderUID,
 		})
@@ -89,17 +103,29 @@ func listCmd(registry grizzly.Registry) *cli.Command {
 
 func pullCmd(registry grizzly.Registry) *cli.Command {
 	cmd := &cli.Command{
-		Use:   "pull <resource-path>",
+		Use:   "pull [<resource-path>]",
 		Short: "Pulls remote resources and writes them to local sources",
-		Args:  cli.ArgsExact(1),
+		Args:  cli.ArgsRange(0, 1),
 	}
 	var opts Opts
 	var continueOnError bool
+	var layout string
+	var sync bool
 
 	cmd.Flags().BoolVarP(&continueOnError, "continue-on-error", "e", false, "don't stop pulling on error")
+	cmd.Flags().BoolVar(&sync, "sync", false, "remove local files describing resources that no longer exist remotely")
+	cmd.Flags().StringVar(&layout, "layout", grizzly.LayoutDefault, "how to lay out pulled resources on disk, one of default, folders (mirrors the folder hierarchy by title)")
 
-	cmd.Run = func(cmd *cli.Command, args []string) error {
-		eventsRecorder := getEventsRecorder(opts)
+	cmd.Run = func(cmd *cli.Command, args []string) (err error) {
+		resourcePath, err := getResourcePath(args)
+		if err != nil {
+			return err
+		}
+		eventsRecorder, closeEvents, err := getEventsRecorder(opts, "pull")
+		if err != nil {
+			return err
+		}
+		defer func() { err = errors.Join(err, closeEvents()) }()
 		format, onlySpec, err := getOutputFormat(opts)
 		if err != nil {
 			return err
@@ -110,9 +136,31 @@ func pullCmd(registry grizzly.Registry) *cli.Command {
 			return err
 		}
 
+		resourceKind, folderUID, err := getOnlySpec(opts)
+		if err != nil {
+			return err
+		}
+
 		targets := currentContext.GetTargets(opts.Targets)
 
-		err = grizzly.Pull(registry, args[0], onlySpec, format, targets, continueOnError, eventsRecorder)
+		if project := config.CurrentProject(); project != nil && project.Layout != "" && !cmd.Flags().Changed("layout") {
+			layout = project.Layout
+		}
+
+		err = grizzly.Pull(registry, resourcePath, grizzly.PullOptions{
+			OnlySpec:        onlySpec,
+			OutputFormat:    format,
+			Targets:         targets,
+			ContinueOnError: continueOnError,
+			Layout:          layout,
+			Sync:            sync,
+			Parser:          newParser(registry, currentContext, nil, opts, grizzly.ParserContinueOnError(true)),
+			ParserOptions: grizzly.ParserOptions{
+				DefaultResourceKind: resourceKind,
+				DefaultFolderUID:    folderUID,
+			},
+			SOPSAgeKeyFile: currentContext.SOPS.AgeKeyFile,
+		}, eventsRecorder)
 
 		notifier.Info(nil, eventsRecorder.Summary().AsString("resource"))
 
@@ -125,19 +173,24 @@ func pullCmd(registry grizzly.Registry) *cli.Command {
 		return nil
 	}
 
+	cmd = initialiseEvents(cmd, &opts)
 	cmd = initialiseOnlySpec(cmd, &opts)
 	return initialiseCmd(cmd, &opts)
 }
 
 func showCmd(registry grizzly.Registry) *cli.Command {
 	cmd := &cli.Command{
-		Use:   "show <resource-path>",
+		Use:   "show [<resource-path>]",
 		Short: "show list of resource types and UIDs",
-		Args:  cli.ArgsExact(1),
+		Args:  cli.ArgsRange(0, 1),
 	}
 	var opts Opts
 
 	cmd.Run = func(cmd *cli.Command, args []string) error {
+		resourcePath, err := getResourcePath(args)
+		if err != nil {
+			return err
+		}
 		resourceKind, folderUID, err := getOnlySpec(opts)
 		if err != nil {
 			return err
@@ -149,7 +202,7 @@ func showCmd(registry grizzly.Registry) *cli.Command {
 		}
 		targets := currentContext.GetTargets(opts.Targets)
 
-		resources, err := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths).Parse(args[0], grizzly.ParserOptions{
+		resources, err := newParser(registry, currentContext, targets, opts).Parse(resourcePath, grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
 			DefaultFolderUID:    folderUID,
 		})
@@ -169,13 +222,17 @@ func showCmd(registry grizzly.Registry) *cli.Command {
 
 func diffCmd(registry grizzly.Registry) *cli.Command {
 	cmd := &cli.Command{
-		Use:   "diff <resource-path>",
+		Use:   "diff [<resource-path>]",
 		Short: "compare local and remote resources",
-		Args:  cli.ArgsExact(1),
+		Args:  cli.ArgsRange(0, 1),
 	}
 	var opts Opts
 
 	cmd.Run = func(cmd *cli.Command, args []string) error {
+		resourcePath, err := getResourcePath(args)
+		if err != nil {
+			return err
+		}
 		resourceKind, folderUID, err := getOnlySpec(opts)
 		if err != nil {
 			return err
@@ -188,7 +245,7 @@ func diffCmd(registry grizzly.Registry) *cli.Command {
 
 		targets := currentContext.GetTargets(opts.Targets)
 
-		resources, err := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths).Parse(args[0], grizzly.ParserOptions{
+		resources, err := newParser(registry, currentContext, targets, opts).Parse(resourcePath, grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
 			DefaultFolderUID:    folderUID,
 		})
@@ -201,25 +258,44 @@ func diffCmd(registry grizzly.Registry) *cli.Command {
 			return err
 		}
 
-		return grizzly.Diff(registry, resources, onlySpec, format)
+		eventsRecorder, closeReport, err := getReportRecorder(registry, grizzly.NewWriterRecorder(io.Discard, grizzly.EventToPlainText), opts, "grr diff")
+		if err != nil {
+			return err
+		}
+
+		return errors.Join(grizzly.Diff(registry, resources, onlySpec, format, eventsRecorder), closeReport())
 	}
+	cmd = initialiseReport(cmd, &opts)
 	return initialiseCmd(cmd, &opts)
 }
 
 func applyCmd(registry grizzly.Registry) *cli.Command {
 	cmd := &cli.Command{
-		Use:     "apply <resource-path>",
+		Use:     "apply [<resource-path>]",
 		Aliases: []string{"push"},
 		Short:   "apply local resources to remote endpoints",
-		Args:    cli.ArgsExact(1),
+		Args:    cli.ArgsRange(0, 1),
 	}
 	var opts Opts
 	var continueOnError bool
 
 	cmd.Flags().BoolVarP(&continueOnError, "continue-on-error", "e", false, "don't stop apply on first error")
 
-	cmd.Run = func(cmd *cli.Command, args []string) error {
-		eventsRecorder := getEventsRecorder(opts)
+	cmd.Run = func(cmd *cli.Command, args []string) (err error) {
+		resourcePath, err := getResourcePath(args)
+		if err != nil {
+			return err
+		}
+		eventsRecorder, closeEvents, err := getEventsRecorder(opts, "apply")
+		if err != nil {
+			return err
+		}
+		defer func() { err = errors.Join(err, closeEvents()) }()
+		eventsRecorder, closeReport, err := getReportRecorder(registry, eventsRecorder, opts, "grr apply")
+		if err != nil {
+			return err
+		}
+		defer func() { err = errors.Join(err, closeReport()) }()
 		resourceKind, folderUID, err := getOnlySpec(opts)
 		if err != nil {
 			return err
@@ -231,9 +307,9 @@ func applyCmd(registry grizzly.Registry) *cli.Command {
 		}
 
 		targets := currentContext.GetTargets(opts.Targets)
-		parser := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths, grizzly.ParserContinueOnError(continueOnError))
+		parser := newParser(registry, currentContext, targets, opts, grizzly.ParserContinueOnError(continueOnError))
 
-		resources, parseErr := parser.Parse(args[0], grizzly.ParserOptions{
+		resources, parseErr := parser.Parse(resourcePath, grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
 			DefaultFolderUID:    folderUID,
 		})
@@ -271,9 +347,108 @@ func applyCmd(registry grizzly.Registry) *cli.Command {
 	}
 
 	cmd = initialiseOnlySpec(cmd, &opts)
+	cmd = initialiseEvents(cmd, &opts)
+	cmd = initialiseReport(cmd, &opts)
 	return initialiseCmd(cmd, &opts)
 }
 
+func deleteCmd(registry grizzly.Registry) *cli.Command {
+	cmd := &cli.Command{
+		Use:   "delete <resource-path>",
+		Short: "delete the remote equivalents of local resources",
+		Args:  cli.ArgsExact(1),
+	}
+	var opts Opts
+	var continueOnError bool
+	var dryRun bool
+	var yes bool
+
+	cmd.Flags().BoolVarP(&continueOnError, "continue-on-error", "e", false, "don't stop delete on first error")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the resources that would be deleted, without deleting them")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask for confirmation before deleting")
+
+	cmd.Run = func(cmd *cli.Command, args []string) (err error) {
+		eventsRecorder, closeEvents, err := getEventsRecorder(opts, "delete")
+		if err != nil {
+			return err
+		}
+		defer func() { err = errors.Join(err, closeEvents()) }()
+		resourceKind, folderUID, err := getOnlySpec(opts)
+		if err != nil {
+			return err
+		}
+
+		currentContext, err := config.CurrentContext()
+		if err != nil {
+			return err
+		}
+
+		targets := currentContext.GetTargets(opts.Targets)
+		parser := newParser(registry, currentContext, targets, opts, grizzly.ParserContinueOnError(false))
+
+		resources, parseErr := parser.Parse(args[0], grizzly.ParserOptions{
+			DefaultResourceKind: resourceKind,
+			DefaultFolderUID:    folderUID,
+		})
+		if parseErr != nil {
+			return parseErr
+		}
+
+		if dryRun {
+			for _, resource := range grizzly.DeletionOrder(registry, resources) {
+				fmt.Println(resource.Ref())
+			}
+			notifier.Info(nil, fmt.Sprintf("%s would be deleted", grizzly.Pluraliser(resources.Len(), "resource")))
+			return nil
+		}
+		if !yes {
+			if err := confirmDeletion(registry, resources); err != nil {
+				return err
+			}
+		}
+
+		notifier.Info(nil, fmt.Sprintf("Deleting %s", grizzly.Pluraliser(resources.Len(), "resource")))
+
+		deleteErr := grizzly.Delete(registry, resources, continueOnError, eventsRecorder)
+
+		notifier.Info(nil, eventsRecorder.Summary().AsString("resource"))
+
+		// errors are already displayed by the `eventsRecorder`, so we return a
+		// "silent" one to ensure that the exit code will be non-zero
+		if deleteErr != nil {
+			return silentError{Err: deleteErr}
+		}
+
+		return nil
+	}
+
+	cmd = initialiseOnlySpec(cmd, &opts)
+	cmd = initialiseEvents(cmd, &opts)
+	return initialiseCmd(cmd, &opts)
+}
+
+// confirmDeletion lists the resources about to be deleted and asks for
+// confirmation on the terminal
+func confirmDeletion(registry grizzly.Registry, resources grizzly.Resources) error {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("deletion must be confirmed with --yes when not run from a terminal")
+	}
+
+	for _, resource := range grizzly.DeletionOrder(registry, resources) {
+		fmt.Fprintln(os.Stderr, resource.Ref())
+	}
+	fmt.Fprintf(os.Stderr, "Delete %s? [y/N] ", grizzly.Pluraliser(resources.Len(), "resource"))
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return fmt.Errorf("deletion cancelled")
+	}
+	return nil
+}
+
 func watchCmd(registry grizzly.Registry) *cli.Command {
 	cmd := &cli.Command{
 		Use:   "watch <dir-to-watch> <resource-path>",
@@ -298,7 +473,7 @@ func watchCmd(registry grizzly.Registry) *cli.Command {
 
 		trailRecorder := grizzly.NewWriterRecorder(os.Stdout, grizzly.EventToPlainText)
 
-		parser := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths, grizzly.ParserContinueOnError(true))
+		parser := newParser(registry, currentContext, targets, opts, grizzly.ParserContinueOnError(true))
 		parserOpts := grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
 			DefaultFolderUID:    folderUID,
@@ -329,7 +504,7 @@ func snapshotCmd(registry grizzly.Registry) *cli.Command {
 			return err
 		}
 		targets := currentContext.GetTargets(opts.Targets)
-		parser := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths, grizzly.ParserContinueOnError(false))
+		parser := newParser(registry, currentContext, targets, opts, grizzly.ParserContinueOnError(false))
 
 		resources, parseErr := parser.Parse(args[0], grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
@@ -388,7 +563,7 @@ func serveCmd(registry grizzly.Registry) *cli.Command {
 		}
 
 		targets := currentContext.GetTargets(opts.Targets)
-		parser := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths, grizzly.ParserContinueOnError(true))
+		parser := newParser(registry, currentContext, targets, opts, grizzly.ParserContinueOnError(true))
 		parserOpts := grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
 			DefaultFolderUID:    folderUID,
@@ -432,15 +607,19 @@ func exportCmd(registry grizzly.Registry) *cli.Command {
 		Short: "render resources and save to a directory",
 		Args:  cli.ArgsExact(2),
 	}
-	var opts Opts
+	var opts ExportOpts
 	var continueOnError bool
 
 	cmd.Flags().BoolVarP(&continueOnError, "continue-on-error", "e", false, "don't stop exporting on error")
+	cmd.Flags().StringVar(&opts.Format, "format", exportFormatGrizzly, fmt.Sprintf("format of the exported files, one of %s", strings.Join(exportFormats(), ", ")))
+	cmd.Flags().StringVar(&opts.Namespace, "namespace", "", "Kubernetes namespace of the exported objects, with the grafana-operator format")
+	cmd.Flags().StringVar(&opts.InstanceSelector, "instance-selector", "dashboards=grafana", "labels of the Grafana instances exported objects apply to, as key=value pairs separated by commas, with the grafana-operator format")
+	cmd.Flags().BoolVar(&opts.ResolveSecrets, "resolve-secrets", false, "write secrets in clear in the exported files, only readable by their owner, with the alertmanager format")
 
-	cmd.Run = func(cmd *cli.Command, args []string) error {
+	cmd.Run = func(cmd *cli.Command, args []string) (err error) {
 		resourcePath := args[0]
 		exportDir := args[1]
-		resourceKind, folderUID, err := getOnlySpec(opts)
+		resourceKind, folderUID, err := getOnlySpec(opts.Opts)
 		if err != nil {
 			return err
 		}
@@ -452,7 +631,7 @@ func exportCmd(registry grizzly.Registry) *cli.Command {
 
 		targets := currentContext.GetTargets(opts.Targets)
 
-		resources, err := grizzly.DefaultParser(registry, targets, opts.JsonnetPaths, grizzly.ParserContinueOnError(continueOnError)).Parse(resourcePath, grizzly.ParserOptions{
+		resources, err := newParser(registry, currentContext, targets, opts.Opts, grizzly.ParserContinueOnError(continueOnError)).Parse(resourcePath, grizzly.ParserOptions{
 			DefaultResourceKind: resourceKind,
 			DefaultFolderUID:    folderUID,
 		})
@@ -460,12 +639,20 @@ func exportCmd(registry grizzly.Registry) *cli.Command {
 			return err
 		}
 
-		format, onlySpec, err := getOutputFormat(opts)
+		if opts.Format != exportFormatGrizzly {
+			return exportAs(opts.Format, exportDir, resources, opts)
+		}
+
+		format, onlySpec, err := getOutputFormat(opts.Opts)
 		if err != nil {
 			return err
 		}
 
-		eventsRecorder := getEventsRecorder(opts)
+		eventsRecorder, closeEvents, err := getEventsRecorder(opts.Opts, "export")
+		if err != nil {
+			return err
+		}
+		defer func() { err = errors.Join(err, closeEvents()) }()
 
 		err = grizzly.Export(eventsRecorder, registry, exportDir, resources, onlySpec, format, continueOnError)
 
@@ -479,8 +666,9 @@ func exportCmd(registry grizzly.Registry) *cli.Command {
 
 		return nil
 	}
-	cmd = initialiseOnlySpec(cmd, &opts)
-	return initialiseCmd(cmd, &opts)
+	cmd = initialiseEvents(cmd, &opts.Opts)
+	cmd = initialiseOnlySpec(cmd, &opts.Opts)
+	return initialiseCmd(cmd, &opts.Opts)
 }
 
 func providersCmd(registry grizzly.Registry) *cli.Command {
@@ -539,6 +727,14 @@ func initialiseCmd(cmd *cli.Command, opts *Opts) *cli.Command {
 
 	cmd.Flags().BoolVar(&opts.DisableStats, "disable-reporting", false, "disable sending of anonymous usage stats to Grafana Labs")
 
+	cmdRun := cmd.Run
+	cmd.Run = func(cmd *cli.Command, args []string) error {
+		if paths := config.CurrentProject().ResolvedJsonnetPaths(); len(paths) != 0 && !cmd.Flags().Changed("jpath") {
+			opts.JsonnetPaths = paths
+		}
+		return cmdRun(cmd, args)
+	}
+
 	return initialiseLogging(cmd, &opts.LoggingOpts)
 }
 
@@ -571,16 +767,124 @@ func initialiseLogging(cmd *cli.Command, loggingOpts *LoggingOpts) *cli.Command
 	return cmd
 }
 
+// newParser returns the parser reading local resources, configured by opts and
+// the current context.
+func newParser(registry grizzly.Registry, currentContext *config.Context, targets []string, opts Opts, parserOpts ...grizzly.ParserOpt) grizzly.Parser {
+	parserOpts = append(parserOpts, grizzly.ParserSOPSAgeKeyFile(currentContext.SOPS.AgeKeyFile))
+	return grizzly.DefaultParser(registry, targets, opts.JsonnetPaths, parserOpts...)
+}
+
+// getResourcePath returns the resource path given as argument, or the one
+// declared by the project file.
+func getResourcePath(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if path := config.CurrentProject().ResolvedResourcePath(); path != "" {
+		return path, nil
+	}
+	return "", fmt.Errorf("a resource path is required, as no %s declares one", config.ProjectFileName)
+}
+
 func getDefaultJsonnetFolders() []string {
 	return []string{"vendor", "lib", "."}
 }
 
-func getEventsRecorder(opts Opts) grizzly.EventsRecorder {
-	wr := grizzly.NewWriterRecorder(os.Stdout, getEventFormatter())
-	if opts.DisableStats || config.UsageStatsDisabled() {
-		return wr
+func initialiseEvents(cmd *cli.Command, opts *Opts) *cli.Command {
+	cmd.Flags().StringVar(&opts.EventsFormat, "events-format", eventsFormatText, "format of the reported events, one of text, json, junit, github")
+	cmd.Flags().StringVar(&opts.EventsOutput, "events-output", "", "file to write the reported events to (defaults to stdout)")
+
+	return cmd
+}
+
+// getEventsRecorder builds the events recorder configured by the
+// --events-format and --events-output flags. The returned function must be
+// called once every event has been recorded: it flushes the recorder and
+// closes its output.
+func getEventsRecorder(opts Opts, operation string) (grizzly.EventsRecorder, func() error, error) {
+	out := os.Stdout
+	if opts.EventsOutput != "" {
+		file, err := os.Create(opts.EventsOutput)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening events output: %w", err)
+		}
+		out = file
+	}
+
+	var recorder grizzly.EventsRecorder
+	switch opts.EventsFormat {
+	case eventsFormatText, "":
+		formatter := grizzly.EventToPlainText
+		if out == os.Stdout {
+			formatter = getEventFormatter()
+		}
+		recorder = grizzly.NewWriterRecorder(out, formatter)
+	case eventsFormatJSON:
+		recorder = grizzly.NewWriterRecorder(out, grizzly.EventToJSON)
+	case eventsFormatGitHub:
+		recorder = grizzly.NewWriterRecorder(out, grizzly.EventToGitHubAnnotation)
+	case eventsFormatJUnit:
+		recorder = grizzly.NewJUnitRecorder(out, operation)
+	default:
+		if out != os.Stdout {
+			_ = out.Close()
+		}
+		return nil, nil, fmt.Errorf("unknown events format '%s', expected one of text, json, junit, github", opts.EventsFormat)
+	}
+
+	if out == os.Stdout && opts.EventsFormat != eventsFormatText && opts.EventsFormat != "" {
+		// stdout only holds the events, to keep them machine-readable
+		notifier.SetOutput(os.Stderr)
+	}
+
+	if !opts.DisableStats && !config.UsageStatsDisabled() {
+		recorder = grizzly.NewUsageRecorder(recorder)
 	}
-	return grizzly.NewUsageRecorder(wr)
+
+	closeEvents := func() error {
+		var err error
+		if flusher, ok := recorder.(grizzly.EventsFlusher); ok {
+			err = flusher.Flush()
+		}
+		if out != os.Stdout {
+			err = errors.Join(err, out.Close())
+		}
+		return err
+	}
+
+	return recorder, closeEvents, nil
+}
+
+func initialiseReport(cmd *cli.Command, opts *Opts) *cli.Command {
+	cmd.Flags().StringVar(&opts.Report, "report", "", "write a Markdown report of the run to the given file")
+
+	return cmd
+}
+
+// getReportRecorder wraps eventsRecorder to write a Markdown report if
+// requested with --report. The returned function writes the report once every
+// event has been recorded.
+func getReportRecorder(registry grizzly.Registry, eventsRecorder grizzly.EventsRecorder, opts Opts, title string) (grizzly.EventsRecorder, func() error, error) {
+	if opts.Report == "" {
+		return eventsRecorder, func() error { return nil }, nil
+	}
+
+	currentContext, err := config.CurrentContext()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Create(opts.Report)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening report: %w", err)
+	}
+
+	reportRecorder := grizzly.NewReportRecorder(eventsRecorder, file, title, registry, currentContext.Grafana.URL)
+	closeReport := func() error {
+		return errors.Join(reportRecorder.Flush(), file.Close())
+	}
+
+	return reportRecorder, closeReport, nil
 }
 
 func getOutputFormat(opts Opts) (string, bool, error) {
@@ -196,7 +196,7 @@ func TestDashboard(t *testing.T) {
 }
 
 func TestDashboardHandler(t *testing.T) {
-	provider := grafana.NewProvider(&testutil.TestContext().Grafana)
+	provider := grafana.NewProvider(&testutil.TestContext().Grafana, &testutil.TestContext().HTTP)
 	handler := grafana.NewDashboardHandler(provider)
 
 	t.Run("get remote dashboard - success", func(t *testing.T) {
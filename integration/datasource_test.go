@@ -12,7 +12,7 @@ import (
 )
 
 func TestDatasources(t *testing.T) {
-	provider := grafana.NewProvider(&testutil.TestContext().Grafana)
+	provider := grafana.NewProvider(&testutil.TestContext().Grafana, &testutil.TestContext().HTTP)
 	handler := grafana.NewDatasourceHandler(provider)
 
 	t.Run("get remote datasource - success", func(t *testing.T) {
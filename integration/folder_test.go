@@ -13,7 +13,7 @@ import (
 )
 
 func TestFolders(t *testing.T) {
-	provider := grafana.NewProvider(&testutil.TestContext().Grafana)
+	provider := grafana.NewProvider(&testutil.TestContext().Grafana, &testutil.TestContext().HTTP)
 	handler := grafana.NewFolderHandler(provider)
 
 	dir := "testdata/folders"
@@ -12,7 +12,7 @@ import (
 )
 
 func TestLibraryElements(t *testing.T) {
-	provider := grafana.NewProvider(&testutil.TestContext().Grafana)
+	provider := grafana.NewProvider(&testutil.TestContext().Grafana, &testutil.TestContext().HTTP)
 	handler := grafana.NewLibraryElementHandler(provider)
 
 	t.Run("create libraryElement - success", func(t *testing.T) {
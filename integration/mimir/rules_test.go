@@ -17,7 +17,7 @@ import (
 )
 
 func TestRules(t *testing.T) {
-	provider := mimir.NewProvider(&testutil.TestContext().Mimir)
+	provider := mimir.NewProvider(&testutil.TestContext().Mimir, &testutil.TestContext().HTTP)
 	handler := provider.GetHandlers()[0]
 
 	t.Run("create rule group", func(t *testing.T) {
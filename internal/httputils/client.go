@@ -1,29 +1,75 @@
 package httputils
 
 import (
+	"crypto/tls"
 	"net/http"
-	"os"
-	"strconv"
 	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
 )
 
 var defaultTimeout = 10 * time.Second
 
-func NewHTTPClient() (*http.Client, error) {
+const defaultMaxRetries = 3
+
+// ClientOptions configures the clients created by NewHTTPClient.
+type ClientOptions struct {
+	// Config holds the HTTP settings of the current context.
+	Config *config.HTTPConfig
+	// Limiter limits the rate of requests. It should be shared by all the
+	// clients of a provider.
+	Limiter *RateLimiter
+	// TLSConfig holds provider-specific TLS settings. They take precedence
+	// over the ones from Config.
+	TLSConfig *tls.Config
+	// Headers are provider-specific headers added to every request. They
+	// take precedence over the ones from Config.
+	Headers map[string]string
+}
+
+func NewHTTPClient(opts ClientOptions) (*http.Client, error) {
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = &config.HTTPConfig{}
+	}
+
 	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
 
-	// TODO: Move this configuration to the global configuration
-	if timeoutStr := os.Getenv("GRIZZLY_HTTP_TIMEOUT"); timeoutStr != "" {
-		timeoutSeconds, err := strconv.Atoi(timeoutStr)
-		if err != nil {
-			return nil, err
-		}
+	maxRetries := defaultMaxRetries
+	if cfg.MaxRetries != nil {
+		maxRetries = *cfg.MaxRetries
+	}
+
+	transport, err := newTransport(cfg, opts.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
 
-		timeout = time.Duration(timeoutSeconds) * time.Second
+	headers := cfg.Headers
+	if len(opts.Headers) != 0 {
+		headers = make(map[string]string, len(cfg.Headers)+len(opts.Headers))
+		for name, value := range cfg.Headers {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+		for name, value := range opts.Headers {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
 	}
 
+	// The timeout is applied to each attempt by the retrying round tripper,
+	// so that waiting between retries doesn't eat into it.
 	return &http.Client{
-		Timeout:   timeout,
-		Transport: &LoggedHTTPRoundTripper{},
+		Transport: &HeadersRoundTripper{
+			Headers: headers,
+			DecoratedTransport: &RetryingRoundTripper{
+				DecoratedTransport: &LoggedHTTPRoundTripper{DecoratedTransport: transport},
+				Limiter:            opts.Limiter,
+				MaxRetries:         maxRetries,
+				Timeout:            timeout,
+			},
+		},
 	}, nil
 }
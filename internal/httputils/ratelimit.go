@@ -0,0 +1,70 @@
+package httputils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+)
+
+// RateLimiter is a token bucket limiting the rate of requests sent to a
+// provider. A nil RateLimiter doesn't limit anything.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter from the rate-limit settings of cfg.
+// It returns nil if no rate limit is configured.
+func NewRateLimiter(cfg *config.HTTPConfig) *RateLimiter {
+	if cfg == nil || cfg.RateLimit <= 0 {
+		return nil
+	}
+
+	burst := max(cfg.RateLimitBurst, 1)
+
+	return &RateLimiter{
+		rate:   float64(cfg.RateLimit),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request can be sent, or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	// Reserve a token, waiting for the bucket to refill if we're in debt.
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
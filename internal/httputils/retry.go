@@ -0,0 +1,202 @@
+package httputils
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryingRoundTripper retries requests failing with a network error, a 429 or
+// a 5xx status, waiting with an exponential backoff and jitter between
+// attempts, or as long as the server asks to with a Retry-After header.
+// Responses asking to wait longer than MaxBackoff are returned as they are.
+// Requests that may have been processed by the server are only retried if
+// their method is idempotent.
+type RetryingRoundTripper struct {
+	DecoratedTransport http.RoundTripper
+	Limiter            *RateLimiter
+	MaxRetries         int
+	// Timeout is applied to each attempt, including reading the response body.
+	Timeout time.Duration
+	// MinBackoff and MaxBackoff bound the delay between attempts. They default
+	// to 500ms and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (rt *RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := http.DefaultTransport
+	if rt.DecoratedTransport != nil {
+		transport = rt.DecoratedTransport
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		if err := rt.Limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := rt.send(transport, attemptReq)
+		if attempt >= rt.MaxRetries || !rt.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay, ok := rt.backoff(attempt, resp)
+		if !ok {
+			log.Debugf("%s %s returned %s, not retrying: Retry-After exceeds %s", req.Method, req.URL.Redacted(), resp.Status, rt.maxBackoff())
+			return resp, err
+		}
+		if resp != nil {
+			log.Debugf("%s %s returned %s, retrying in %s", req.Method, req.URL.Redacted(), resp.Status, delay)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		} else {
+			log.Debugf("%s %s failed: %v, retrying in %s", req.Method, req.URL.Redacted(), err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (rt *RetryingRoundTripper) send(transport http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if rt.Timeout <= 0 {
+		return transport.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), rt.Timeout)
+	resp, err := transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+func (rt *RetryingRoundTripper) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	// Without a way to rewind the body, the request can't be sent again.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return isIdempotent(req)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// The request was rejected before being processed.
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req)
+	}
+
+	return false
+}
+
+// backoff returns the delay to wait before the next attempt, and false if the
+// server asks to wait longer than MaxBackoff.
+func (rt *RetryingRoundTripper) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	maxBackoff := rt.maxBackoff()
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return delay, delay <= maxBackoff
+		}
+	}
+
+	minBackoff := rt.MinBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+
+	delay := maxBackoff
+	if attempt < 32 {
+		delay = min(minBackoff<<attempt, maxBackoff)
+	}
+
+	// Wait between half and the whole delay, to spread retries from
+	// concurrent clients.
+	return delay/2 + rand.N(delay/2+1), true
+}
+
+func (rt *RetryingRoundTripper) maxBackoff() time.Duration {
+	if rt.MaxBackoff <= 0 {
+		return defaultMaxBackoff
+	}
+	return rt.MaxBackoff
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either in
+// seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Until(date), 0), true
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	// Same convention as net/http, for requests made idempotent by the caller.
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// cancelOnClose cancels the context of a request once its response body has
+// been closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,135 @@
+package httputils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryingRoundTripper(t *testing.T) {
+	// failingServer fails the first `failures` requests with the given status.
+	failingServer := func(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+		t.Helper()
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if calls.Add(1) <= failures {
+				for key, values := range header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = w.Write(body)
+		}))
+		t.Cleanup(server.Close)
+		return server, &calls
+	}
+	newClient := func(maxRetries int) *http.Client {
+		return &http.Client{
+			Transport: &RetryingRoundTripper{
+				MaxRetries: maxRetries,
+				Timeout:    time.Second,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: 2 * time.Millisecond,
+			},
+		}
+	}
+
+	t.Run("idempotent requests are retried on 5xx", func(t *testing.T) {
+		server, calls := failingServer(t, 2, http.StatusBadGateway, nil)
+
+		resp, err := newClient(3).Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		server, calls := failingServer(t, 10, http.StatusServiceUnavailable, nil)
+
+		resp, err := newClient(2).Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("non-idempotent requests are not retried on 5xx", func(t *testing.T) {
+		server, calls := failingServer(t, 1, http.StatusInternalServerError, nil)
+
+		resp, err := newClient(3).Post(server.URL, "text/plain", strings.NewReader("payload"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("rate limited requests are retried with their body", func(t *testing.T) {
+		server, calls := failingServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}})
+
+		resp, err := newClient(3).Post(server.URL, "text/plain", strings.NewReader("payload"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "payload", string(body))
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("responses asking to wait beyond the maximum backoff are returned", func(t *testing.T) {
+		server, calls := failingServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"86400"}})
+
+		start := time.Now()
+		resp, err := newClient(3).Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Less(t, time.Since(start), time.Second)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, "86400", resp.Header.Get("Retry-After"))
+		require.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, ok := parseRetryAfter("7")
+	require.True(t, ok)
+	require.Equal(t, 7*time.Second, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	require.True(t, ok)
+	require.Equal(t, time.Duration(0), delay)
+
+	_, ok = parseRetryAfter("soon")
+	require.False(t, ok)
+}
+
+func TestRateLimiter(t *testing.T) {
+	require.Nil(t, NewRateLimiter(&config.HTTPConfig{}))
+
+	limiter := NewRateLimiter(&config.HTTPConfig{RateLimit: 20, RateLimitBurst: 2})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, limiter.Wait(context.Background()))
+	}
+
+	// The burst is consumed immediately, the two following requests wait
+	// for 1/20th of a second each.
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
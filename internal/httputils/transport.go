@@ -0,0 +1,99 @@
+package httputils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/grafana/grizzly/pkg/config"
+)
+
+// newTransport creates the transport sending requests, configured with the
+// proxy and TLS settings of cfg.
+func newTransport(cfg *config.HTTPConfig, tlsConfig *tls.Config) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+
+	if cfg.CAPath != "" && tlsConfig.RootCAs == nil {
+		certPool, err := LoadCertPool(cfg.CAPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if (cfg.ClientCertPath != "" || cfg.ClientKeyPath != "") && len(tlsConfig.Certificates) == 0 {
+		clientCert, err := tls.LoadX509KeyPair(cfg.ClientCertPath, cfg.ClientKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// LoadCertPool returns the system certificates pool, extended with the
+// certificates of the CA bundle at caPath.
+func LoadCertPool(caPath string) (*x509.CertPool, error) {
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	caCertPEM, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok := certPool.AppendCertsFromPEM(caCertPEM); !ok {
+		return nil, fmt.Errorf("could not append ca-bundle at path %s to existing certificates", caPath)
+	}
+
+	return certPool, nil
+}
+
+// HeadersRoundTripper adds headers to requests, unless they are already set.
+type HeadersRoundTripper struct {
+	DecoratedTransport http.RoundTripper
+	Headers            map[string]string
+}
+
+func (rt *HeadersRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := http.DefaultTransport
+	if rt.DecoratedTransport != nil {
+		transport = rt.DecoratedTransport
+	}
+
+	if len(rt.Headers) == 0 {
+		return transport.RoundTrip(req)
+	}
+
+	// Round trippers must not modify the request they're given.
+	req = req.Clone(req.Context())
+	for name, value := range rt.Headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	return transport.RoundTrip(req)
+}
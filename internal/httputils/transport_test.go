@@ -0,0 +1,89 @@
+package httputils
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("headers are added unless already set", func(t *testing.T) {
+		var received http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+		}))
+		defer server.Close()
+
+		client, err := NewHTTPClient(ClientOptions{
+			Config: &config.HTTPConfig{
+				Headers: map[string]string{"x-scope": "team-a", "x-overridden": "default"},
+			},
+		})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Overridden", "explicit")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, "team-a", received.Get("X-Scope"))
+		require.Equal(t, "explicit", received.Get("X-Overridden"))
+		require.Equal(t, "explicit", req.Header.Get("X-Overridden"))
+	})
+
+	t.Run("requests go through the configured proxy", func(t *testing.T) {
+		var proxiedHost string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxiedHost = r.Host
+		}))
+		defer proxy.Close()
+
+		client, err := NewHTTPClient(ClientOptions{
+			Config: &config.HTTPConfig{ProxyURL: proxy.URL},
+		})
+		require.NoError(t, err)
+
+		resp, err := client.Get("http://grafana.example.invalid/api/health")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, "grafana.example.invalid", proxiedHost)
+	})
+
+	t.Run("servers are trusted using the configured CA bundle", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		caPath := filepath.Join(t.TempDir(), "ca.pem")
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		require.NoError(t, os.WriteFile(caPath, caPEM, 0600))
+
+		untrusting, err := NewHTTPClient(ClientOptions{Config: &config.HTTPConfig{MaxRetries: new(int)}})
+		require.NoError(t, err)
+		_, err = untrusting.Get(server.URL)
+		require.Error(t, err)
+
+		client, err := NewHTTPClient(ClientOptions{Config: &config.HTTPConfig{CAPath: caPath}})
+		require.NoError(t, err)
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("invalid settings are reported", func(t *testing.T) {
+		_, err := NewHTTPClient(ClientOptions{Config: &config.HTTPConfig{CAPath: filepath.Join(t.TempDir(), "missing.pem")}})
+		require.Error(t, err)
+
+		_, err = NewHTTPClient(ClientOptions{Config: &config.HTTPConfig{ProxyURL: "://nope"}})
+		require.Error(t, err)
+	})
+}
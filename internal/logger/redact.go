@@ -8,23 +8,16 @@ import (
 )
 
 type SecretsRedactor struct {
-	replacementsMap map[string]string
+	secrets func() []string
 }
 
-func NewSecretsRedactor(secrets []string) *SecretsRedactor {
-	redactor := &SecretsRedactor{
-		replacementsMap: make(map[string]string, len(secrets)),
+// NewSecretsRedactor creates a hook redacting secrets from logs. secrets is
+// called for every log entry, so that secrets resolved while running are
+// redacted too.
+func NewSecretsRedactor(secrets func() []string) *SecretsRedactor {
+	return &SecretsRedactor{
+		secrets: secrets,
 	}
-
-	for _, secret := range secrets {
-		replacement := "**REDACTED**"
-		if len(secret) >= 20 {
-			replacement = secret[:9] + "..." + secret[len(secret)-5:]
-		}
-		redactor.replacementsMap[secret] = replacement
-	}
-
-	return redactor
 }
 
 func (h *SecretsRedactor) Levels() []logrus.Level {
@@ -89,12 +82,19 @@ func (h *SecretsRedactor) redactStructFields(src, dest reflect.Value) {
 }
 
 func (h *SecretsRedactor) redactString(s string) string {
-	for secret, redacted := range h.replacementsMap {
-		s = strings.ReplaceAll(s, secret, redacted)
+	for _, secret := range h.secrets() {
+		s = strings.ReplaceAll(s, secret, redactedSecret(secret))
 	}
 	return s
 }
 
+func redactedSecret(secret string) string {
+	if len(secret) >= 20 {
+		return secret[:9] + "..." + secret[len(secret)-5:]
+	}
+	return "**REDACTED**"
+}
+
 func (h *SecretsRedactor) reflectValueIsNil(value reflect.Value) bool {
 	kind := value.Kind()
 	return (kind == reflect.Pointer || kind == reflect.Interface || kind == reflect.Array || kind == reflect.Slice || kind == reflect.Map) && value.IsNil()
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -22,6 +24,14 @@ const (
 	DisableReportingSetting = "disable-reporting"
 )
 
+// ConfigEnvVar is the environment variable selecting where the configuration
+// lives. Setting it to "none" makes the configuration ephemeral: no settings
+// file is read or written, contexts are built from environment variables and
+// flags only.
+const ConfigEnvVar = "GRIZZLY_CONFIG"
+
+var ErrEphemeralConfig = errors.New("the configuration is ephemeral (" + ConfigEnvVar + "=none) and cannot be written")
+
 // Version is the current version of the grr command.
 // To be overwritten at build time
 var Version = "dev"
@@ -29,16 +39,26 @@ var Version = "dev"
 func Initialise() {
 	viper.SetConfigName("settings")
 	viper.SetConfigType("yaml")
+	viper.SetConfigPermissions(0600)
+	if Ephemeral() {
+		return
+	}
 	viper.AddConfigPath(".")
 	viper.AddConfigPath(configdir.LocalConfig("grizzly"))
-	viper.SetConfigPermissions(0600)
+}
+
+// Ephemeral tells whether the configuration only lives in memory, as requested
+// with GRIZZLY_CONFIG=none.
+func Ephemeral() bool {
+	return os.Getenv(ConfigEnvVar) == "none"
 }
 
 func override(v *viper.Viper) {
 	bindings := map[string]string{
-		"grafana.url":   "GRAFANA_URL",
-		"grafana.user":  "GRAFANA_USER",
-		"grafana.token": "GRAFANA_TOKEN",
+		"grafana.url":    "GRAFANA_URL",
+		"grafana.user":   "GRAFANA_USER",
+		"grafana.token":  "GRAFANA_TOKEN",
+		"grafana.org-id": "GRAFANA_ORG_ID",
 
 		"synthetic-monitoring.access-token": "GRAFANA_SM_ACCESS_TOKEN",
 		"synthetic-monitoring.token":        "GRAFANA_SM_TOKEN",
@@ -51,6 +71,8 @@ func override(v *viper.Viper) {
 		"mimir.tenant-id":  "MIMIR_TENANT_ID",
 		"mimir.api-key":    "MIMIR_API_KEY",
 		"mimir.auth-token": "MIMIR_AUTH_TOKEN",
+
+		"http.timeout": "GRIZZLY_HTTP_TIMEOUT",
 	}
 
 	// To keep retro compatibility
@@ -80,7 +102,17 @@ func getVal(env string, alternativeMap map[string]string) string {
 }
 
 func Read() error {
-	err := viper.ReadInConfig()
+	var err error
+	if project, err = FindProject("."); err != nil {
+		return err
+	}
+
+	if Ephemeral() {
+		NewConfig()
+		return nil
+	}
+
+	err = viper.ReadInConfig()
 	if err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			NewConfig()
@@ -127,6 +159,11 @@ func GetContexts() ([]string, error) {
 	if err := viper.UnmarshalKey("contexts", &contexts); err != nil {
 		return nil, err
 	}
+	if project != nil {
+		for k := range project.Contexts {
+			contexts[k] = struct{}{}
+		}
+	}
 	keys := make([]string, 0, len(contexts))
 	for k := range contexts {
 		keys = append(keys, k)
@@ -136,11 +173,11 @@ func GetContexts() ([]string, error) {
 }
 
 func UseContext(context string) error {
-	contexts := map[string]interface{}{}
-	if err := viper.UnmarshalKey("contexts", &contexts); err != nil {
+	contexts, err := GetContexts()
+	if err != nil {
 		return err
 	}
-	for k := range contexts {
+	for _, k := range contexts {
 		if k == context {
 			viper.Set(CurrentContextSetting, context)
 			return Write()
@@ -153,49 +190,168 @@ func UsageStatsDisabled() bool {
 	return viper.GetBool(DisableReportingSetting)
 }
 
-func CurrentContext() (*Context, error) {
+// CurrentContextName returns the name of the current context, without reading
+// its settings.
+func CurrentContextName() string {
 	name := viper.GetString(CurrentContextSetting)
 	if name == "" {
 		NewConfig()
-		return CurrentContext()
+		return viper.GetString(CurrentContextSetting)
 	}
-	contextPath := fmt.Sprintf("contexts.%s", name)
-	ctx := viper.Sub(contextPath)
-	if ctx == nil {
-		ctx = viper.New()
+	return name
+}
+
+// CurrentContext returns the settings of the current context, with the
+// references they hold interpolated.
+func CurrentContext() (*Context, error) {
+	name := CurrentContextName()
+	ctx, err := contextSettings(name)
+	if err != nil {
+		return nil, err
 	}
-	override(ctx)
 	var context Context
 	if err := ctx.Unmarshal(&context); err != nil {
 		return nil, err
 	}
+	if err := interpolate(&context); err != nil {
+		return nil, fmt.Errorf("context %s: %w", name, err)
+	}
 	context.Name = name
 	return &context, nil
 }
 
+// contextSettings returns the effective settings of the given context,
+// overridden by environment variables.
+func contextSettings(name string) (*viper.Viper, error) {
+	settings, err := resolveContext(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := viper.New()
+	if err := ctx.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("merging settings of context %s: %w", name, err)
+	}
+	override(ctx)
+	return ctx, nil
+}
+
+// resolveContext returns the effective settings of the given context: the
+// defaults of the project file, then the settings of the contexts it extends,
+// then its own settings, each one deep-merged on top of the previous ones.
+func resolveContext(name string) (map[string]any, error) {
+	var names []string
+	var chain []*viper.Viper
+	for current := name; current != ""; {
+		if slices.Contains(names, current) {
+			return nil, fmt.Errorf("context %s: extends cycle %s -> %s", name, strings.Join(names, " -> "), current)
+		}
+		names = append(names, current)
+
+		own, found, err := ownSettings(current)
+		if err != nil {
+			return nil, err
+		}
+		if !found && current != name {
+			return nil, fmt.Errorf("context %s extends unknown context %s", names[len(names)-2], current)
+		}
+		chain = append(chain, own)
+		current = own.GetString("extends")
+	}
+
+	resolved := viper.New()
+	if err := resolved.MergeConfigMap(project.defaults()); err != nil {
+		return nil, fmt.Errorf("merging project defaults: %w", err)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := resolved.MergeConfigMap(chain[i].AllSettings()); err != nil {
+			return nil, fmt.Errorf("merging settings of context %s: %w", names[i], err)
+		}
+	}
+
+	return resolved.AllSettings(), nil
+}
+
+// ownSettings returns the settings declared for the given context itself: those
+// of the project file, overridden by the user configuration. It also tells
+// whether the context is declared at all.
+func ownSettings(name string) (*viper.Viper, bool, error) {
+	own := viper.New()
+	found := false
+
+	if project != nil && project.Contexts[name] != nil {
+		found = true
+		if err := own.MergeConfigMap(project.contextSettings(name)); err != nil {
+			return nil, false, fmt.Errorf("merging project settings of context %s: %w", name, err)
+		}
+	}
+	if user := viper.Sub(fmt.Sprintf("contexts.%s", name)); user != nil {
+		found = true
+		if err := own.MergeConfigMap(user.AllSettings()); err != nil {
+			return nil, false, fmt.Errorf("merging settings of context %s: %w", name, err)
+		}
+	}
+
+	return own, found, nil
+}
+
 var acceptableKeys = map[string]string{
-	"grafana.url":                       "string",
-	"grafana.token":                     "string",
-	"grafana.user":                      "string",
-	"grafana.insecure-skip-verify":      "bool",
-	"grafana.tls-host":                  "string",
-	"mimir.address":                     "string",
-	"mimir.tenant-id":                   "string",
-	"mimir.api-key":                     "string",
-	"mimir.auth-token":                  "string",
-	"synthetic-monitoring.access-token": "string",
-	"synthetic-monitoring.token":        "string",
-	"synthetic-monitoring.stack-id":     "int",
-	"synthetic-monitoring.metrics-id":   "int",
-	"synthetic-monitoring.logs-id":      "int",
-	"synthetic-monitoring.url":          "string",
-	"targets":                           "[]string",
-	"output-format":                     "string",
-	"only-spec":                         "bool",
+	"grafana.url":                               "string",
+	"grafana.token":                             "string",
+	"grafana.user":                              "string",
+	"grafana.insecure-skip-verify":              "bool",
+	"grafana.tls-host":                          "string",
+	"grafana.token-command":                     "[]string",
+	"grafana.token-file":                        "string",
+	"grafana.org-id":                            "int",
+	"grafana.org-name":                          "string",
+	"grafana.notification-policy-routes":        "bool",
+	"mimir.address":                             "string",
+	"mimir.tenant-id":                           "string",
+	"mimir.api-key":                             "string",
+	"mimir.auth-token":                          "string",
+	"mimir.api-key-command":                     "[]string",
+	"mimir.api-key-file":                        "string",
+	"mimir.auth-token-command":                  "[]string",
+	"mimir.auth-token-file":                     "string",
+	"synthetic-monitoring.access-token":         "string",
+	"synthetic-monitoring.token":                "string",
+	"synthetic-monitoring.stack-id":             "int",
+	"synthetic-monitoring.metrics-id":           "int",
+	"synthetic-monitoring.logs-id":              "int",
+	"synthetic-monitoring.url":                  "string",
+	"synthetic-monitoring.token-command":        "[]string",
+	"synthetic-monitoring.token-file":           "string",
+	"synthetic-monitoring.access-token-command": "[]string",
+	"synthetic-monitoring.access-token-file":    "string",
+	"http.timeout":                              "int",
+	"http.proxy-url":                            "string",
+	"http.ca-path":                              "string",
+	"http.client-cert-path":                     "string",
+	"http.client-key-path":                      "string",
+	"http.max-retries":                          "int",
+	"http.rate-limit":                           "int",
+	"http.rate-limit-burst":                     "int",
+	"sops.age-key-file":                         "string",
+	"extends":                                   "string",
+	"targets":                                   "[]string",
+	"output-format":                             "string",
+	"only-spec":                                 "bool",
 }
 
+// Hash returns a hash of the configuration, in which contexts are replaced by
+// their effective settings. Contexts that cannot be resolved are hashed as is.
 func Hash() (string, error) {
 	cfg := viper.AllSettings()
+
+	if contexts, ok := cfg["contexts"].(map[string]any); ok {
+		for name := range contexts {
+			if settings, err := resolveContext(name); err == nil {
+				contexts[name] = settings
+			}
+		}
+	}
+
 	out := sha256.New()
 	err := json.NewEncoder(out).Encode(cfg)
 	if err != nil {
@@ -205,13 +361,10 @@ func Hash() (string, error) {
 }
 
 func Get(path, outputFormat string) (string, error) {
-	ctx := viper.GetString(CurrentContextSetting)
-
-	vCtx := viper.Sub(fmt.Sprintf("contexts.%s", ctx))
-	if vCtx == nil {
-		vCtx = viper.New()
+	vCtx, err := contextSettings(viper.GetString(CurrentContextSetting))
+	if err != nil {
+		return "", err
 	}
-	override(vCtx)
 
 	var val any
 	val = vCtx.AllSettings()
@@ -280,6 +433,10 @@ func Set(path string, value string) error {
 }
 
 func Unset(path string) error {
+	if Ephemeral() {
+		return ErrEphemeralConfig
+	}
+
 	exists := false
 	for k := range acceptableKeys {
 		if path == k {
@@ -329,6 +486,10 @@ func CreateContext(name string) error {
 }
 
 func Write() error {
+	if Ephemeral() {
+		return ErrEphemeralConfig
+	}
+
 	err := viper.WriteConfig()
 	if err == nil {
 		return nil
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEphemeralConfig(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "settings.yaml"), []byte("current-context: from-file\n"), 0600))
+
+	t.Setenv(ConfigEnvVar, "none")
+	t.Setenv("GRAFANA_URL", "https://grafana.example.com")
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	Initialise()
+	require.NoError(t, Read())
+
+	context, err := CurrentContext()
+	require.NoError(t, err)
+	require.Equal(t, "default", context.Name)
+	require.Equal(t, "https://grafana.example.com", context.Grafana.URL)
+
+	require.ErrorIs(t, Set("grafana.user", "admin"), ErrEphemeralConfig)
+	require.ErrorIs(t, CreateContext("other"), ErrEphemeralConfig)
+	require.ErrorIs(t, Import(), ErrEphemeralConfig)
+
+	content, err := os.ReadFile(filepath.Join(dir, "settings.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "current-context: from-file\n", string(content))
+}
+
+func TestContextExtends(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+current-context: prod
+contexts:
+  base:
+    targets: [Dashboard]
+    output-format: json
+    mimir:
+      tls:
+        ca-path: /etc/ca.pem
+  staging:
+    extends: base
+    grafana:
+      url: https://staging.grafana.example.com
+      token: staging-token
+  prod:
+    extends: staging
+    grafana:
+      url: https://grafana.example.com
+    mimir:
+      address: https://mimir.example.com
+  loop-a:
+    extends: loop-b
+  loop-b:
+    extends: loop-a
+  orphan:
+    extends: missing
+`)))
+
+	t.Run("settings are inherited and deep-merged", func(t *testing.T) {
+		context, err := CurrentContext()
+		require.NoError(t, err)
+		require.Equal(t, "https://grafana.example.com", context.Grafana.URL)
+		require.Equal(t, "staging-token", context.Grafana.Token)
+		require.Equal(t, "https://mimir.example.com", context.Mimir.Address)
+		require.Equal(t, "/etc/ca.pem", context.Mimir.TLS.CAPath)
+		require.Equal(t, []string{"Dashboard"}, context.Targets)
+		require.Equal(t, "staging", context.Extends)
+	})
+
+	t.Run("effective values are shown", func(t *testing.T) {
+		value, err := Get("mimir.tls.ca-path", "yaml")
+		require.NoError(t, err)
+		require.Equal(t, "/etc/ca.pem\n", value)
+	})
+
+	t.Run("hash reflects inherited values", func(t *testing.T) {
+		before, err := Hash()
+		require.NoError(t, err)
+
+		viper.Set("contexts.base.output-format", "yaml")
+		after, err := Hash()
+		require.NoError(t, err)
+		require.NotEqual(t, before, after)
+	})
+
+	t.Run("invalid inheritance is reported", func(t *testing.T) {
+		viper.Set(CurrentContextSetting, "loop-a")
+		_, err := CurrentContext()
+		require.ErrorContains(t, err, "extends cycle loop-a -> loop-b -> loop-a")
+
+		viper.Set(CurrentContextSetting, "orphan")
+		_, err = CurrentContext()
+		require.ErrorContains(t, err, "context orphan extends unknown context missing")
+	})
+}
+
+func TestCurrentContextName(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+
+	require.NoError(t, viper.ReadConfig(strings.NewReader(`
+current-context: broken
+contexts:
+  broken:
+    grafana:
+      url: ${GRIZZLY_TEST_UNSET}
+`)))
+
+	_, err := CurrentContext()
+	require.ErrorContains(t, err, "environment variable GRIZZLY_TEST_UNSET is not set")
+
+	require.Equal(t, "broken", CurrentContextName())
+	url, err := Get("grafana.url", "yaml")
+	require.NoError(t, err)
+	require.Equal(t, "${GRIZZLY_TEST_UNSET}\n", url)
+}
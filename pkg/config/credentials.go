@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// credentialsMu serializes the resolution of credentials, so that a credential
+// helper is only run once even if several clients are built concurrently.
+var credentialsMu sync.Mutex
+
+// ResolveCredentials obtains the Grafana token from its credential helper or
+// file, unless it is already set.
+func (c *GrafanaConfig) ResolveCredentials() error {
+	return resolveCredential("grafana.token", &c.Token, c.TokenCommand, c.TokenFile)
+}
+
+// ResolveCredentials obtains the Mimir API key and auth token from their
+// credential helper or file, unless they are already set.
+func (c *MimirConfig) ResolveCredentials() error {
+	return errors.Join(
+		resolveCredential("mimir.api-key", &c.APIKey, c.APIKeyCommand, c.APIKeyFile),
+		resolveCredential("mimir.auth-token", &c.AuthToken, c.AuthTokenCommand, c.AuthTokenFile),
+	)
+}
+
+// ResolveCredentials obtains the Synthetic Monitoring tokens from their
+// credential helper or file, unless they are already set.
+func (c *SyntheticMonitoringConfig) ResolveCredentials() error {
+	return errors.Join(
+		resolveCredential("synthetic-monitoring.token", &c.Token, c.TokenCommand, c.TokenFile),
+		resolveCredential("synthetic-monitoring.access-token", &c.AccessToken, c.AccessTokenCommand, c.AccessTokenFile),
+	)
+}
+
+// resolveCredential sets value to the output of command, or to the content of
+// file. Resolved values are kept in the configuration for the rest of the run,
+// which also makes them part of Context.Secrets().
+func resolveCredential(name string, value *string, command []string, file string) error {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+
+	if *value != "" {
+		return nil
+	}
+
+	switch {
+	case len(command) != 0:
+		var stdout bytes.Buffer
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("running credential helper for %s: %w", name, err)
+		}
+		*value = strings.TrimSpace(stdout.String())
+
+	case file != "":
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("reading credential file for %s: %w", name, err)
+		}
+		*value = strings.TrimSpace(string(content))
+
+	default:
+		return nil
+	}
+
+	if *value == "" {
+		return fmt.Errorf("credential for %s is empty", name)
+	}
+
+	return nil
+}
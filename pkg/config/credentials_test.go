@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCredentials(t *testing.T) {
+	t.Run("tokens are obtained from credential helpers", func(t *testing.T) {
+		context := Context{
+			Grafana: GrafanaConfig{TokenCommand: []string{"echo", "from-helper"}},
+		}
+
+		require.NoError(t, context.Grafana.ResolveCredentials())
+		require.Equal(t, "from-helper", context.Grafana.Token)
+		require.Contains(t, context.Secrets(), "from-helper")
+	})
+
+	t.Run("tokens are read from files", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0600))
+
+		mimir := MimirConfig{AuthTokenFile: file}
+
+		require.NoError(t, mimir.ResolveCredentials())
+		require.Equal(t, "from-file", mimir.AuthToken)
+		require.Empty(t, mimir.APIKey)
+	})
+
+	t.Run("explicit tokens take precedence", func(t *testing.T) {
+		sm := SyntheticMonitoringConfig{AccessToken: "explicit", AccessTokenCommand: []string{"false"}}
+
+		require.NoError(t, sm.ResolveCredentials())
+		require.Equal(t, "explicit", sm.AccessToken)
+	})
+
+	t.Run("failures are reported", func(t *testing.T) {
+		failing := GrafanaConfig{TokenCommand: []string{"false"}}
+		require.ErrorContains(t, failing.ResolveCredentials(), "running credential helper for grafana.token")
+
+		empty := GrafanaConfig{TokenCommand: []string{"true"}}
+		require.ErrorContains(t, empty.ResolveCredentials(), "credential for grafana.token is empty")
+
+		missing := GrafanaConfig{TokenFile: filepath.Join(t.TempDir(), "missing")}
+		require.ErrorContains(t, missing.ResolveCredentials(), "reading credential file for grafana.token")
+	})
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// interpolationRegex matches `${ENV_VAR}` and `${file:/path}` references, as
+// well as `$${`, used to write a literal `${`.
+var interpolationRegex = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+// interpolate replaces references to environment variables and files in all
+// the string values held by v, which must be a pointer.
+func interpolate(v any) error {
+	return interpolateValue(reflect.ValueOf(v).Elem())
+}
+
+func interpolateValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		value, err := interpolateString(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(value)
+
+	case reflect.Struct:
+		var errs []error
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := interpolateValue(v.Field(i)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+
+	case reflect.Slice:
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolateValue(v.Index(i)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+
+		var errs []error
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := interpolateString(iter.Value().String())
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(value).Convert(v.Type().Elem()))
+		}
+		return errors.Join(errs...)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return interpolateValue(v.Elem())
+	}
+
+	return nil
+}
+
+func interpolateString(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var errs []error
+	result := interpolationRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		reference := match[2 : len(match)-1]
+		value, err := resolveReference(reference)
+		if err != nil {
+			errs = append(errs, err)
+			return match
+		}
+		return value
+	})
+
+	return result, errors.Join(errs...)
+}
+
+func resolveReference(reference string) (string, error) {
+	if path, ok := strings.CutPrefix(reference, "file:"); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("interpolating ${%s}: %w", reference, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	value, ok := os.LookupEnv(reference)
+	if !ok {
+		return "", fmt.Errorf("interpolating ${%s}: environment variable %s is not set", reference, reference)
+	}
+	return value, nil
+}
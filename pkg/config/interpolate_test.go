@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("GRIZZLY_TEST_HOST", "grafana.example.com")
+	t.Setenv("GRIZZLY_TEST_TENANT", "tenant-1")
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s3cr3t\n"), 0600))
+
+	t.Run("references are replaced in all string values", func(t *testing.T) {
+		context := Context{
+			Grafana: GrafanaConfig{
+				URL:   "https://${GRIZZLY_TEST_HOST}/",
+				Token: "${file:" + tokenFile + "}",
+			},
+			Mimir:   MimirConfig{TenantID: "${GRIZZLY_TEST_TENANT}"},
+			Targets: []string{"Dashboard.${GRIZZLY_TEST_TENANT}"},
+			HTTP: HTTPConfig{
+				Headers: map[string]string{"x-tenant": "${GRIZZLY_TEST_TENANT}"},
+			},
+		}
+
+		require.NoError(t, interpolate(&context))
+		require.Equal(t, "https://grafana.example.com/", context.Grafana.URL)
+		require.Equal(t, "s3cr3t", context.Grafana.Token)
+		require.Equal(t, "tenant-1", context.Mimir.TenantID)
+		require.Equal(t, []string{"Dashboard.tenant-1"}, context.Targets)
+		require.Equal(t, "tenant-1", context.HTTP.Headers["x-tenant"])
+	})
+
+	t.Run("references can be escaped", func(t *testing.T) {
+		context := Context{Grafana: GrafanaConfig{Token: "pa$$word-$${GRIZZLY_TEST_HOST}"}}
+
+		require.NoError(t, interpolate(&context))
+		require.Equal(t, "pa$$word-${GRIZZLY_TEST_HOST}", context.Grafana.Token)
+	})
+
+	t.Run("unresolvable references are reported", func(t *testing.T) {
+		context := Context{
+			Grafana: GrafanaConfig{
+				URL:   "${GRIZZLY_TEST_UNSET}",
+				Token: "${file:" + filepath.Join(t.TempDir(), "missing") + "}",
+			},
+		}
+
+		err := interpolate(&context)
+		require.ErrorContains(t, err, "environment variable GRIZZLY_TEST_UNSET is not set")
+		require.ErrorContains(t, err, "missing")
+	})
+}
@@ -1,11 +1,26 @@
 package config
 
+import "strings"
+
 type GrafanaConfig struct {
 	URL                string `yaml:"url" mapstructure:"url"`
 	User               string `yaml:"user" mapstructure:"user"`
 	Token              string `yaml:"token" mapstructure:"token"`
 	InsecureSkipVerify bool   `yaml:"insecure-skip-verify" mapstructure:"insecure-skip-verify"`
 	TLSHost            string `yaml:"tls-host" mapstructure:"tls-host"`
+	// The token can alternatively be obtained from a credential helper
+	// command printing it, or read from a file.
+	TokenCommand []string `yaml:"token-command" mapstructure:"token-command"`
+	TokenFile    string   `yaml:"token-file" mapstructure:"token-file"`
+	// OrgID is the organization requests are sent to. It can alternatively
+	// be given by name with OrgName. Defaults to the organization of the
+	// credentials.
+	OrgID   int64  `yaml:"org-id" mapstructure:"org-id"`
+	OrgName string `yaml:"org-name" mapstructure:"org-name"`
+	// NotificationPolicyRoutes lets AlertNotificationPolicyRoute resources
+	// own the top-level routes of the notification policy with
+	// object_matchers, which the AlertNotificationPolicy then leaves alone.
+	NotificationPolicyRoutes bool `yaml:"notification-policy-routes" mapstructure:"notification-policy-routes"`
 }
 
 type MimirConfig struct {
@@ -14,6 +29,12 @@ type MimirConfig struct {
 	APIKey    string         `yaml:"api-key" mapstructure:"api-key"`
 	TLS       MimirTLSConfig `yaml:"tls" mapstructure:"tls"`
 	AuthToken string         `yaml:"auth-token" mapstructure:"auth-token"`
+	// The API key and auth token can alternatively be obtained from a
+	// credential helper command printing them, or read from a file.
+	APIKeyCommand    []string `yaml:"api-key-command" mapstructure:"api-key-command"`
+	APIKeyFile       string   `yaml:"api-key-file" mapstructure:"api-key-file"`
+	AuthTokenCommand []string `yaml:"auth-token-command" mapstructure:"auth-token-command"`
+	AuthTokenFile    string   `yaml:"auth-token-file" mapstructure:"auth-token-file"`
 }
 
 type MimirTLSConfig struct {
@@ -30,6 +51,49 @@ type SyntheticMonitoringConfig struct {
 	LogsID      int64  `yaml:"logs-id" mapstructure:"logs-id"`
 	MetricsID   int64  `yaml:"metrics-id" mapstructure:"metrics-id"`
 	AccessToken string `yaml:"access-token" mapstructure:"access-token"`
+	// The tokens can alternatively be obtained from a credential helper
+	// command printing them, or read from a file.
+	TokenCommand       []string `yaml:"token-command" mapstructure:"token-command"`
+	TokenFile          string   `yaml:"token-file" mapstructure:"token-file"`
+	AccessTokenCommand []string `yaml:"access-token-command" mapstructure:"access-token-command"`
+	AccessTokenFile    string   `yaml:"access-token-file" mapstructure:"access-token-file"`
+}
+
+// HTTPConfig holds the settings shared by the HTTP clients of all providers.
+type HTTPConfig struct {
+	// Timeout is the timeout of each request, in seconds. Defaults to 10.
+	Timeout int `yaml:"timeout" mapstructure:"timeout"`
+	// ProxyURL is the URL of the proxy to send requests through. Defaults to
+	// the proxy set by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+	// variables.
+	ProxyURL string `yaml:"proxy-url" mapstructure:"proxy-url"`
+	// CAPath is the path to a CA bundle trusted in addition to the system
+	// certificates.
+	CAPath string `yaml:"ca-path" mapstructure:"ca-path"`
+	// ClientCertPath and ClientKeyPath are the paths to a client certificate
+	// and its key, used for mutual TLS.
+	ClientCertPath string `yaml:"client-cert-path" mapstructure:"client-cert-path"`
+	ClientKeyPath  string `yaml:"client-key-path" mapstructure:"client-key-path"`
+	// Headers are added to every request. The values of headers carrying
+	// credentials, such as Authorization or X-Api-Key, are secrets.
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	// MaxRetries is the number of times a request failing with a network
+	// error, a 429 or a 5xx status is retried. Defaults to 3, 0 disables retries.
+	MaxRetries *int `yaml:"max-retries" mapstructure:"max-retries"`
+	// RateLimit is the maximum number of requests per second sent to each
+	// provider. Requests are not limited if it is 0.
+	RateLimit int `yaml:"rate-limit" mapstructure:"rate-limit"`
+	// RateLimitBurst is the number of requests that can be sent at once
+	// before being limited. Defaults to 1.
+	RateLimitBurst int `yaml:"rate-limit-burst" mapstructure:"rate-limit-burst"`
+}
+
+// SOPSConfig holds the settings used to decrypt resource files encrypted with
+// SOPS.
+type SOPSConfig struct {
+	// AgeKeyFile is the file holding the age keys. Defaults to the location
+	// used by sops.
+	AgeKeyFile string `yaml:"age-key-file" mapstructure:"age-key-file"`
 }
 
 type Context struct {
@@ -37,11 +101,16 @@ type Context struct {
 	Grafana             GrafanaConfig             `yaml:"grafana" mapstructure:"grafana"`
 	Mimir               MimirConfig               `yaml:"mimir" mapstructure:"mimir"`
 	SyntheticMonitoring SyntheticMonitoringConfig `yaml:"synthetic-monitoring" mapstructure:"synthetic-monitoring"`
+	HTTP                HTTPConfig                `yaml:"http" mapstructure:"http"`
+	SOPS                SOPSConfig                `yaml:"sops" mapstructure:"sops"`
 	Targets             []string                  `yaml:"targets" mapstructure:"targets"`
 	OutputFormat        string                    `yaml:"output-format" mapstructure:"output-format"`
 	OnlySpec            bool                      `yaml:"only-spec" mapstructure:"only-spec"`
 	ResourceKind        string                    `yaml:"resource-kind" mapstructure:"resource-kind"`
 	FolderUID           string                    `yaml:"folder-uid" mapstructure:"folder-uid"`
+	// Extends is the name of a context whose settings are inherited, and
+	// deep-merged with those of this context.
+	Extends string `yaml:"extends" mapstructure:"extends"`
 }
 
 // Secrets returns all the secrets contained in the current context.
@@ -50,9 +119,15 @@ func (c Context) Secrets() []string {
 	candidates := []string{
 		c.Grafana.Token,
 		c.Mimir.APIKey,
+		c.Mimir.AuthToken,
 		c.SyntheticMonitoring.Token,
 		c.SyntheticMonitoring.AccessToken,
 	}
+	for name, value := range c.HTTP.Headers {
+		if isSecretHeader(name) {
+			candidates = append(candidates, value)
+		}
+	}
 
 	secrets := make([]string, 0, len(candidates))
 	for _, candidate := range candidates {
@@ -63,3 +138,18 @@ func (c Context) Secrets() []string {
 
 	return secrets
 }
+
+// secretHeaderSuffixes are the suffixes of the names of headers carrying
+// credentials, e.g. X-Api-Key or X-Auth-Token.
+var secretHeaderSuffixes = []string{"authorization", "cookie", "token", "key", "secret", "password"}
+
+// isSecretHeader tells whether a header carries credentials, judging by its name.
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range secretHeaderSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
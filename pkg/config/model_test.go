@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextSecrets(t *testing.T) {
+	context := Context{
+		Grafana: GrafanaConfig{Token: "grafana-token"},
+		HTTP: HTTPConfig{
+			Headers: map[string]string{
+				"Authorization": "Bearer abc",
+				"X-Api-Key":     "api-key",
+				"X-Auth-Token":  "auth-token",
+				"X-Scope-OrgID": "1",
+				"Accept":        "application/json",
+			},
+		},
+	}
+
+	require.ElementsMatch(t, []string{"grafana-token", "Bearer abc", "api-key", "auth-token"}, context.Secrets())
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the name of the project file, discovered in the current
+// directory or any of its parents.
+const ProjectFileName = "grizzly.yaml"
+
+// Project holds the settings of a project file. Project files are meant to be
+// checked into a repository: they declare how resources are laid out and
+// which settings each context uses, while credentials stay in the user
+// configuration.
+type Project struct {
+	// Path is the path to the project file.
+	Path string `yaml:"-"`
+	// ResourcePath is used by commands when no resource path is given.
+	ResourcePath string `yaml:"resource-path"`
+	// JsonnetPaths are used as library search dirs when -J is not given.
+	JsonnetPaths []string `yaml:"jsonnet-paths"`
+	// Targets and OutputFormat are the defaults of every context.
+	Targets      []string `yaml:"targets"`
+	OutputFormat string   `yaml:"output-format"`
+	// Layout is used by `grr pull` when --layout is not given.
+	Layout string `yaml:"layout"`
+	// Contexts holds per-environment settings, merged beneath the user
+	// context of the same name.
+	Contexts map[string]map[string]any `yaml:"contexts"`
+}
+
+var project *Project
+
+// CurrentProject returns the project file found when reading the
+// configuration, or nil if there is none.
+func CurrentProject() *Project {
+	return project
+}
+
+// FindProject looks for a project file in dir and its parents. It returns nil
+// if none is found.
+func FindProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		_, err := os.Stat(path)
+		if err == nil {
+			return LoadProject(path)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads the project file at path.
+func LoadProject(path string) (*Project, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Project{}
+	if err := yaml.Unmarshal(content, p); err != nil {
+		return nil, fmt.Errorf("parsing project file %s: %w", path, err)
+	}
+	p.Path = path
+
+	return p, nil
+}
+
+// ResolvePath makes a path from the project file relative to the current
+// directory, so that it designates the same location wherever grr is run
+// from within the project.
+func (p *Project) ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	resolved := filepath.Join(filepath.Dir(p.Path), path)
+	wd, err := os.Getwd()
+	if err != nil {
+		return resolved
+	}
+	if relative, err := filepath.Rel(wd, resolved); err == nil {
+		return relative
+	}
+
+	return resolved
+}
+
+// ResolvedResourcePath returns the default resource path, relative to the
+// current directory, or an empty string if the project does not declare one.
+func (p *Project) ResolvedResourcePath() string {
+	if p == nil || p.ResourcePath == "" {
+		return ""
+	}
+	return p.ResolvePath(p.ResourcePath)
+}
+
+// ResolvedJsonnetPaths returns the jsonnet library search dirs, relative to
+// the current directory.
+func (p *Project) ResolvedJsonnetPaths() []string {
+	if p == nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(p.JsonnetPaths))
+	for _, path := range p.JsonnetPaths {
+		paths = append(paths, p.ResolvePath(path))
+	}
+	return paths
+}
+
+// defaults returns the settings the project declares for every context.
+func (p *Project) defaults() map[string]any {
+	settings := map[string]any{}
+	if p == nil {
+		return settings
+	}
+
+	if len(p.Targets) != 0 {
+		settings["targets"] = append([]string(nil), p.Targets...)
+	}
+	if p.OutputFormat != "" {
+		settings["output-format"] = p.OutputFormat
+	}
+
+	return settings
+}
+
+// contextSettings returns the settings the project declares for the given
+// context. The returned map is a copy that can safely be merged into.
+func (p *Project) contextSettings(name string) map[string]any {
+	if p == nil {
+		return map[string]any{}
+	}
+	return copySettings(p.Contexts[name])
+}
+
+func copySettings(settings map[string]any) map[string]any {
+	copied := make(map[string]any, len(settings))
+	for k, v := range settings {
+		if nested, ok := v.(map[string]any); ok {
+			v = copySettings(nested)
+		}
+		copied[k] = v
+	}
+	return copied
+}
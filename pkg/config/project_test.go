@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProject(t *testing.T) {
+	dir := t.TempDir()
+	subdir := filepath.Join(dir, "dashboards", "team")
+	require.NoError(t, os.MkdirAll(subdir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ProjectFileName), []byte(`
+resource-path: resources
+jsonnet-paths: [vendor, lib]
+targets: [Dashboard/*]
+output-format: json
+layout: folders
+contexts:
+  staging:
+    grafana:
+      url: https://staging.grafana.example.com
+      user: project-user
+    mimir:
+      tenant-id: "1234"
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(subdir, "settings.yaml"), []byte(`
+current-context: staging
+contexts:
+  staging:
+    grafana:
+      user: admin
+      token: s3cr3t
+`), 0600))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(subdir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Cleanup(func() { project = nil })
+
+	Initialise()
+	require.NoError(t, Read())
+
+	t.Run("project file is discovered up the directory tree", func(t *testing.T) {
+		p := CurrentProject()
+		require.NotNil(t, p)
+		require.Equal(t, "folders", p.Layout)
+		require.Equal(t, filepath.Join("..", "..", "resources"), p.ResolvedResourcePath())
+		require.Equal(t, []string{filepath.Join("..", "..", "vendor"), filepath.Join("..", "..", "lib")}, p.ResolvedJsonnetPaths())
+	})
+
+	t.Run("project settings are merged beneath user contexts", func(t *testing.T) {
+		context, err := CurrentContext()
+		require.NoError(t, err)
+		require.Equal(t, "https://staging.grafana.example.com", context.Grafana.URL)
+		require.Equal(t, "admin", context.Grafana.User)
+		require.Equal(t, "s3cr3t", context.Grafana.Token)
+		require.Equal(t, "1234", context.Mimir.TenantID)
+		require.Equal(t, []string{"Dashboard/*"}, context.Targets)
+		require.Equal(t, "json", context.OutputFormat)
+
+		// merging must not alter the project settings
+		require.Equal(t, "project-user", CurrentProject().Contexts["staging"]["grafana"].(map[string]any)["user"])
+	})
+
+	t.Run("project contexts can be used", func(t *testing.T) {
+		contexts, err := GetContexts()
+		require.NoError(t, err)
+		require.Equal(t, []string{"staging"}, contexts)
+	})
+}
@@ -0,0 +1,220 @@
+package grafana
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+// defaultPrometheusEvaluationInterval is the interval rule groups are
+// evaluated at by Prometheus and Mimir, when they don't specify one.
+const defaultPrometheusEvaluationInterval = time.Minute
+
+// PrometheusConversionOptions describes where converted Prometheus rules
+// query their data from, and where they are stored.
+type PrometheusConversionOptions struct {
+	DatasourceUID string
+	FolderUID     string
+}
+
+var prometheusDurationRegexp = regexp.MustCompile(`^(?:(\d+)y)?(?:(\d+)w)?(?:(\d+)d)?(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?(?:(\d+)ms)?$`)
+
+// values in templates of Prometheus rules refer to the value of the query
+var prometheusValueRegexp = regexp.MustCompile(`\$value\b`)
+
+// ConvertPrometheusRuleGroup converts a PrometheusRuleGroup resource into an
+// AlertRuleGroup resource. Each alerting rule becomes a Grafana-managed rule
+// querying the rule expression, firing for every series it returns like
+// Prometheus does. Recording rules can't be converted: they are skipped and
+// reported in the returned warnings.
+func (h *AlertRuleGroupHandler) ConvertPrometheusRuleGroup(resource grizzly.Resource, opts PrometheusConversionOptions) (*grizzly.Resource, []string, error) {
+	interval := defaultPrometheusEvaluationInterval
+	if value, ok := resource.GetSpecString("interval"); ok {
+		parsed, err := parsePrometheusDuration(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("interval of %s: %w", resource.Ref(), err)
+		}
+		interval = parsed
+	}
+
+	rules, _ := resource.GetSpecValue("rules").([]any)
+	convertedRules := make([]any, 0, len(rules))
+	titles := map[string]int{}
+	var warnings []string
+	for i, item := range rules {
+		rule, ok := item.(map[string]any)
+		if !ok {
+			return nil, nil, fmt.Errorf("rule %d of %s is not an object", i, resource.Ref())
+		}
+
+		if record, isRecording := recordingRuleName(rule); isRecording {
+			warnings = append(warnings, fmt.Sprintf("recording rule %s skipped", record))
+			continue
+		}
+
+		converted, err := convertPrometheusRule(rule, resource.Name(), opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule %d of %s: %w", i, resource.Ref(), err)
+		}
+
+		// titles are unique within a group
+		title := converted["title"].(string)
+		titles[title]++
+		if titles[title] > 1 {
+			converted["title"] = fmt.Sprintf("%s (%d)", title, titles[title])
+			warnings = append(warnings, fmt.Sprintf("alerting rule %s renamed to %s", title, converted["title"]))
+		}
+
+		convertedRules = append(convertedRules, converted)
+	}
+
+	spec := map[string]any{
+		"folderUid": opts.FolderUID,
+		"interval":  int64(interval.Seconds()),
+		"title":     resource.Name(),
+		"rules":     convertedRules,
+	}
+	converted, err := grizzly.NewResource(h.APIVersion(), h.Kind(), joinAlertRuleGroupUID(opts.FolderUID, resource.Name()), spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &converted, warnings, nil
+}
+
+func convertPrometheusRule(rule map[string]any, group string, opts PrometheusConversionOptions) (map[string]any, error) {
+	title, _ := rule["alert"].(string)
+	if title == "" && rule["type"] == "alerting" {
+		title, _ = rule["name"].(string)
+	}
+	if title == "" {
+		return nil, fmt.Errorf("alert name not specified")
+	}
+
+	expr, _ := rule["expr"].(string)
+	if expr == "" {
+		expr, _ = rule["query"].(string)
+	}
+	if expr == "" {
+		return nil, fmt.Errorf("expr of %s not specified", title)
+	}
+
+	var pending time.Duration
+	switch value := rule["for"].(type) {
+	case nil:
+	case string:
+		parsed, err := parsePrometheusDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("for of %s: %w", title, err)
+		}
+		pending = parsed
+	default:
+		return nil, fmt.Errorf("for of %s must be a duration, got %v", title, value)
+	}
+	// rules listed by the Mimir API hold a duration in seconds instead
+	if seconds, ok := rule["duration"].(float64); ok && rule["for"] == nil {
+		pending = time.Duration(seconds * float64(time.Second))
+	}
+
+	converted := map[string]any{
+		"title":        title,
+		"condition":    "C",
+		"data":         prometheusRuleQueries(expr, opts.DatasourceUID),
+		"for":          pending.String(),
+		"noDataState":  "OK",
+		"execErrState": "Error",
+		"folderUID":    opts.FolderUID,
+		"ruleGroup":    group,
+	}
+	for _, field := range []string{"labels", "annotations"} {
+		if templates := convertPrometheusTemplates(rule[field]); len(templates) != 0 {
+			converted[field] = templates
+		}
+	}
+	return converted, nil
+}
+
+// prometheusRuleQueries returns the data entries of a converted rule: the
+// query (A), its last value (B) templates refer to as $value, and the
+// condition (C), true for every series the query returns whatever its value.
+func prometheusRuleQueries(expr, datasourceUID string) []any {
+	expression := func(refID string, model map[string]any) map[string]any {
+		model["refId"] = refID
+		model["datasource"] = map[string]any{"type": "__expr__", "uid": "__expr__"}
+		return map[string]any{
+			"refId":             refID,
+			"datasourceUid":     "__expr__",
+			"relativeTimeRange": map[string]any{"from": 0, "to": 0},
+			"model":             model,
+		}
+	}
+
+	return []any{
+		map[string]any{
+			"refId":             "A",
+			"datasourceUid":     datasourceUID,
+			"relativeTimeRange": map[string]any{"from": 600, "to": 0},
+			"model": map[string]any{
+				"refId":         "A",
+				"datasource":    map[string]any{"type": "prometheus", "uid": datasourceUID},
+				"expr":          expr,
+				"instant":       true,
+				"range":         false,
+				"intervalMs":    1000,
+				"maxDataPoints": 43200,
+			},
+		},
+		expression("B", map[string]any{"type": "reduce", "expression": "A", "reducer": "last"}),
+		expression("C", map[string]any{"type": "math", "expression": "is_number($A) || is_nan($A) || is_inf($A)"}),
+	}
+}
+
+// convertPrometheusTemplates rewrites references to $value in labels or
+// annotations, for them to refer to the value of the query.
+func convertPrometheusTemplates(value any) map[string]any {
+	templates, _ := value.(map[string]any)
+	converted := make(map[string]any, len(templates))
+	for name, template := range templates {
+		if text, ok := template.(string); ok {
+			template = prometheusValueRegexp.ReplaceAllString(text, "$$values.B.Value")
+		}
+		converted[name] = template
+	}
+	return converted
+}
+
+func recordingRuleName(rule map[string]any) (string, bool) {
+	if record, ok := rule["record"].(string); ok {
+		return record, true
+	}
+	if rule["type"] == "recording" {
+		name, _ := rule["name"].(string)
+		return name, true
+	}
+	return "", false
+}
+
+// parsePrometheusDuration parses durations such as "1d12h", which
+// Prometheus supports but time.ParseDuration doesn't.
+func parsePrometheusDuration(value string) (time.Duration, error) {
+	matches := prometheusDurationRegexp.FindStringSubmatch(value)
+	if value == "" || matches == nil {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+
+	units := []time.Duration{365 * 24 * time.Hour, 7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second, time.Millisecond}
+	var duration time.Duration
+	for i, unit := range units {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(matches[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s': %w", value, err)
+		}
+		duration += time.Duration(n) * unit
+	}
+	return duration, nil
+}
@@ -0,0 +1,106 @@
+package grafana
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleGroupHandler_ConvertPrometheusRuleGroup(t *testing.T) {
+	handler := NewAlertRuleGroupHandler(&Provider{})
+	opts := PrometheusConversionOptions{DatasourceUID: "prom", FolderUID: "alerts"}
+
+	resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", "PrometheusRuleGroup", "availability", map[string]any{
+		"interval": "2m",
+		"rules": []any{
+			map[string]any{
+				"alert":       "TargetDown",
+				"expr":        "up == 0",
+				"for":         "1d",
+				"labels":      map[string]any{"severity": "critical"},
+				"annotations": map[string]any{"summary": "{{ $labels.job }} is down ({{ $value }}), {{ $values.A }}"},
+			},
+			map[string]any{"alert": "TargetDown", "expr": "up{job=\"api\"} == 0"},
+			map[string]any{"record": "job:up:sum", "expr": "sum by(job) (up)"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("alerting rules are converted", func(t *testing.T) {
+		converted, warnings, err := handler.ConvertPrometheusRuleGroup(resource, opts)
+		require.NoError(t, err)
+		require.NoError(t, handler.Validate(*converted))
+		require.Equal(t, "alerts.availability", converted.Name())
+		require.Equal(t, []string{
+			"alerting rule TargetDown renamed to TargetDown (2)",
+			"recording rule job:up:sum skipped",
+		}, warnings)
+
+		require.EqualValues(t, 120, converted.GetSpecValue("interval"))
+		rules := converted.GetSpecValue("rules").([]any)
+		require.Len(t, rules, 2)
+
+		rule := rules[0].(map[string]any)
+		require.Equal(t, "TargetDown", rule["title"])
+		require.Equal(t, "24h0m0s", rule["for"])
+		require.Equal(t, "C", rule["condition"])
+		require.NotContains(t, rule, "orgID")
+		require.Equal(t, "availability", rule["ruleGroup"])
+		require.Equal(t, map[string]any{"severity": "critical"}, rule["labels"])
+		require.Equal(t, map[string]any{"summary": "{{ $labels.job }} is down ({{ $values.B.Value }}), {{ $values.A }}"}, rule["annotations"])
+
+		data := rule["data"].([]any)
+		require.Len(t, data, 3)
+		query := data[0].(map[string]any)
+		require.Equal(t, "A", query["refId"])
+		require.Equal(t, "prom", query["datasourceUid"])
+		require.Equal(t, "up == 0", query["model"].(map[string]any)["expr"])
+		require.Equal(t, true, query["model"].(map[string]any)["instant"])
+
+		// $value refers to the last value of each series
+		last := data[1].(map[string]any)
+		require.Equal(t, "B", last["refId"])
+		require.Equal(t, "__expr__", last["datasourceUid"])
+		require.Equal(t, map[string]any{
+			"refId":      "B",
+			"datasource": map[string]any{"type": "__expr__", "uid": "__expr__"},
+			"type":       "reduce",
+			"expression": "A",
+			"reducer":    "last",
+		}, last["model"])
+
+		// the condition holds for every series returned, whatever its value
+		condition := data[2].(map[string]any)
+		require.Equal(t, "C", condition["refId"])
+		require.Equal(t, "__expr__", condition["datasourceUid"])
+		require.Equal(t, map[string]any{
+			"refId":      "C",
+			"datasource": map[string]any{"type": "__expr__", "uid": "__expr__"},
+			"type":       "math",
+			"expression": "is_number($A) || is_nan($A) || is_inf($A)",
+		}, condition["model"])
+
+		second := rules[1].(map[string]any)
+		require.Equal(t, "TargetDown (2)", second["title"])
+		require.Equal(t, "0s", second["for"])
+		require.NotContains(t, second, "labels")
+	})
+
+	t.Run("groups are evaluated every minute by default", func(t *testing.T) {
+		resource.DeleteSpecKey("interval")
+		converted, _, err := handler.ConvertPrometheusRuleGroup(resource, opts)
+		require.NoError(t, err)
+		require.EqualValues(t, 60, converted.GetSpecValue("interval"))
+	})
+
+	t.Run("durations are parsed like Prometheus", func(t *testing.T) {
+		duration, err := parsePrometheusDuration("1w2d3h4m5s6ms")
+		require.NoError(t, err)
+		require.Equal(t, 9*24*time.Hour+3*time.Hour+4*time.Minute+5*time.Second+6*time.Millisecond, duration)
+
+		_, err = parsePrometheusDuration("5 minutes")
+		require.ErrorContains(t, err, "invalid duration '5 minutes'")
+	})
+}
@@ -77,9 +77,32 @@ func (h *AlertRuleGroupHandler) GetByUID(uid string) (*grizzly.Resource, error)
 	return h.getRemoteAlertRuleGroup(uid)
 }
 
-// GetRemote retrieves a alertRuleGroup as a Resource
+// GetRemote retrieves a alertRuleGroup as a Resource. The orgID of remote
+// rules is left out when the local rule leaves it to the organization it is
+// applied to.
 func (h *AlertRuleGroupHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
-	return h.getRemoteAlertRuleGroup(resource.Name())
+	remote, err := h.getRemoteAlertRuleGroup(resource.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	withOrgID := map[string]bool{}
+	localRules, _ := resource.GetSpecValue("rules").([]any)
+	for _, rule := range localRules {
+		if rule, ok := rule.(map[string]any); ok {
+			title, _ := rule["title"].(string)
+			withOrgID[title] = rule["orgID"] != nil
+		}
+	}
+	remoteRules, _ := remote.GetSpecValue("rules").([]any)
+	for _, rule := range remoteRules {
+		if rule, ok := rule.(map[string]any); ok {
+			if title, _ := rule["title"].(string); !withOrgID[title] {
+				delete(rule, "orgID")
+			}
+		}
+	}
+	return remote, nil
 }
 
 // ListRemote retrieves as list of UIDs of all remote resources
@@ -1,8 +1,10 @@
 package grafana
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/grizzly"
 	"github.com/stretchr/testify/require"
 )
@@ -19,3 +21,37 @@ func TestAlertRuleGroupHandler_ResourceFilePath(t *testing.T) {
 		req.Equal("alert-rules/alertRuleGroup-some-alert-group.yaml", handler.ResourceFilePath(resource, "yaml"))
 	})
 }
+
+func TestAlertRuleGroupHandler_GetRemote(t *testing.T) {
+	server := newFakeGrafana(t, map[string]http.HandlerFunc{
+		"GET /api/v1/provisioning/folder/alerts/rule-groups/availability": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]any{
+				"title":     "availability",
+				"folderUid": "alerts",
+				"interval":  60,
+				"rules": []any{
+					map[string]any{"title": "TargetDown", "orgID": 1},
+					map[string]any{"title": "Pinned", "orgID": 1},
+				},
+			})
+		},
+	})
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+	handler := NewAlertRuleGroupHandler(provider)
+
+	t.Run("remote rules leave out the orgID local rules leave out", func(t *testing.T) {
+		resource, err := grizzly.NewResource(handler.APIVersion(), handler.Kind(), "alerts.availability", map[string]any{
+			"rules": []any{
+				map[string]any{"title": "TargetDown"},
+				map[string]any{"title": "Pinned", "orgID": 1},
+			},
+		})
+		require.NoError(t, err)
+
+		remote, err := handler.GetRemote(resource)
+		require.NoError(t, err)
+		rules := remote.GetSpecValue("rules").([]any)
+		require.NotContains(t, rules[0], "orgID")
+		require.EqualValues(t, 1, rules[1].(map[string]any)["orgID"])
+	})
+}
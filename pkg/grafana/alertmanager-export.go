@@ -0,0 +1,443 @@
+package grafana
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	alertmanagerConfigFile   = "alertmanager.yml"
+	alertmanagerTemplatesDir = "templates"
+)
+
+type alertmanagerConfig struct {
+	Route         *alertmanagerRoute     `yaml:"route"`
+	Receivers     []alertmanagerReceiver `yaml:"receivers"`
+	TimeIntervals []any                  `yaml:"time_intervals,omitempty"`
+	Templates     []string               `yaml:"templates,omitempty"`
+}
+
+type alertmanagerRoute struct {
+	Receiver            string               `yaml:"receiver,omitempty"`
+	GroupBy             []string             `yaml:"group_by,omitempty"`
+	Matchers            []string             `yaml:"matchers,omitempty"`
+	Continue            bool                 `yaml:"continue,omitempty"`
+	GroupWait           string               `yaml:"group_wait,omitempty"`
+	GroupInterval       string               `yaml:"group_interval,omitempty"`
+	RepeatInterval      string               `yaml:"repeat_interval,omitempty"`
+	MuteTimeIntervals   []string             `yaml:"mute_time_intervals,omitempty"`
+	ActiveTimeIntervals []string             `yaml:"active_time_intervals,omitempty"`
+	Routes              []*alertmanagerRoute `yaml:"routes,omitempty"`
+}
+
+type alertmanagerReceiver struct {
+	Name    string           `yaml:"name"`
+	Configs map[string][]any `yaml:",inline"`
+}
+
+// alertmanagerIntegration converts the settings of a Grafana integration into
+// the configuration of the equivalent Alertmanager integration.
+type alertmanagerIntegration struct {
+	key     string
+	convert func(settings *integrationSettings) (map[string]any, error)
+}
+
+// alertmanagerIntegrations lists the Grafana integrations with an
+// Alertmanager equivalent, by type
+var alertmanagerIntegrations = map[string]alertmanagerIntegration{
+	"email": {key: "email_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		addresses := regexp.MustCompile(`[;,\n]`).Split(s.string("addresses"), -1)
+		to := make([]string, 0, len(addresses))
+		for _, address := range addresses {
+			if address = strings.TrimSpace(address); address != "" {
+				to = append(to, address)
+			}
+		}
+		config := map[string]any{"to": strings.Join(to, ", ")}
+		if subject := s.string("subject"); subject != "" {
+			config["headers"] = map[string]any{"Subject": subject}
+		}
+		return config, nil
+	}},
+	"slack": {key: "slack_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		config := s.copy(map[string]string{"recipient": "channel", "username": "username", "icon_emoji": "icon_emoji", "icon_url": "icon_url", "title": "title", "text": "text"})
+		if s.has("token") {
+			authorization := map[string]any{}
+			s.set(authorization, "token", "credentials")
+			config["api_url"] = "https://slack.com/api/chat.postMessage"
+			config["http_config"] = map[string]any{"authorization": authorization}
+		} else {
+			s.set(config, "url", "api_url")
+		}
+		return config, nil
+	}},
+	"pagerduty": {key: "pagerduty_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		return s.copy(map[string]string{"integrationKey": "routing_key", "severity": "severity", "class": "class", "component": "component", "group": "group", "summary": "description", "source": "source", "client": "client", "client_url": "client_url"}), nil
+	}},
+	"webhook": {key: "webhook_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		if method := s.string("httpMethod"); method != "" && method != "POST" {
+			return nil, fmt.Errorf("only POST webhooks are supported, got %s", method)
+		}
+		config := s.copy(map[string]string{"url": "url"})
+		httpConfig := map[string]any{}
+		if username := s.string("username"); username != "" {
+			basicAuth := map[string]any{"username": username}
+			s.set(basicAuth, "password", "password")
+			httpConfig["basic_auth"] = basicAuth
+		}
+		if s.has("authorization_credentials") {
+			authorization := map[string]any{"type": s.string("authorization_scheme")}
+			s.set(authorization, "authorization_credentials", "credentials")
+			httpConfig["authorization"] = authorization
+		}
+		if len(httpConfig) != 0 {
+			config["http_config"] = httpConfig
+		}
+		if maxAlerts := s.string("maxAlerts"); maxAlerts != "" && maxAlerts != "0" {
+			n, err := strconv.Atoi(maxAlerts)
+			if err != nil {
+				return nil, fmt.Errorf("maxAlerts must be a number, got %s", maxAlerts)
+			}
+			config["max_alerts"] = n
+		}
+		return config, nil
+	}},
+	"opsgenie": {key: "opsgenie_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		config := s.copy(map[string]string{"apiKey": "api_key", "message": "message", "description": "description"})
+		if apiURL := s.string("apiUrl"); apiURL != "" {
+			// Grafana calls the alerts endpoint, Alertmanager the base URL of the API
+			config["api_url"] = strings.TrimSuffix(strings.TrimSuffix(apiURL, "/"), "v2/alerts")
+		}
+		return config, nil
+	}},
+	"telegram": {key: "telegram_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		config := s.copy(map[string]string{"bottoken": "bot_token", "message": "message", "parse_mode": "parse_mode"})
+		chatID, err := strconv.ParseInt(s.string("chatid"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("chatid must be a number, got %s", s.string("chatid"))
+		}
+		config["chat_id"] = chatID
+		return config, nil
+	}},
+	"discord": {key: "discord_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		return s.copy(map[string]string{"url": "webhook_url", "title": "title", "message": "message"}), nil
+	}},
+	"teams": {key: "msteams_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		return s.copy(map[string]string{"url": "webhook_url", "title": "title", "message": "text"}), nil
+	}},
+	"pushover": {key: "pushover_configs", convert: func(s *integrationSettings) (map[string]any, error) {
+		return s.copy(map[string]string{"userKey": "user_key", "apiToken": "token", "title": "title", "message": "message", "priority": "priority", "sound": "sound", "expire": "expire", "retry": "retry"}), nil
+	}},
+}
+
+// alertmanagerFileSettings lists the Alertmanager settings that can be read
+// from a file, with the same name suffixed by _file
+var alertmanagerFileSettings = map[string]bool{
+	"api_url": true, "routing_key": true, "url": true, "api_key": true, "bot_token": true,
+	"webhook_url": true, "user_key": true, "token": true, "credentials": true, "password": true,
+}
+
+// integrationSettings keeps track of the settings of an integration that
+// were exported, to report the others. Settings holding references to
+// secret files are kept apart, as paths.
+type integrationSettings struct {
+	values map[string]any
+	files  map[string]string
+	used   map[string]bool
+}
+
+func (s *integrationSettings) has(key string) bool {
+	_, isFile := s.files[key]
+	return isFile || s.string(key) != ""
+}
+
+// set sets the Alertmanager setting name of config to the value of the
+// setting key, or to the file it's read from
+func (s *integrationSettings) set(config map[string]any, key string, name string) {
+	if file, isFile := s.files[key]; isFile {
+		if alertmanagerFileSettings[name] {
+			s.used[key] = true
+			config[name+"_file"] = file
+		}
+		return
+	}
+	if value := s.string(key); value != "" {
+		config[name] = value
+	}
+}
+
+func (s *integrationSettings) string(key string) string {
+	s.used[key] = true
+	value := s.values[key]
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// copy returns the non-empty settings, named after their Alertmanager equivalent
+func (s *integrationSettings) copy(names map[string]string) map[string]any {
+	config := map[string]any{}
+	for grafanaName, alertmanagerName := range names {
+		s.set(config, grafanaName, alertmanagerName)
+	}
+	return config
+}
+
+func (s *integrationSettings) unused() []string {
+	var keys []string
+	for key, value := range s.values {
+		if s.used[key] || value == nil || value == "" || value == false {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	for key := range s.files {
+		if !s.used[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AlertmanagerOptions describes how resources are exported to an
+// Alertmanager configuration.
+type AlertmanagerOptions struct {
+	// ResolveSecrets writes the secrets references designate in clear.
+	// Otherwise, secrets read from files are read by Alertmanager from the
+	// same files, and others are left out.
+	ResolveSecrets bool
+}
+
+// ExportAlertmanagerConfig translates alerting resources into the
+// configuration of a standalone Alertmanager: contact points into receivers,
+// the notification policy (and its subtrees) into the route tree, mute
+// timings into time intervals and notification templates into template
+// files. It returns the files to write, by path, and warnings about what has
+// no Alertmanager equivalent.
+func ExportAlertmanagerConfig(resources grizzly.Resources, opts AlertmanagerOptions) (map[string][]byte, []string, error) {
+	var warnings []string
+	files := map[string][]byte{}
+
+	policies := resources.OfKind(AlertNotificationPolicyKind).AsList()
+	if len(policies) != 1 {
+		return nil, nil, fmt.Errorf("exactly one %s is required to export an Alertmanager configuration, found %d", AlertNotificationPolicyKind, len(policies))
+	}
+	tree, err := mergedPolicyTree(policies[0], resources.OfKind(AlertNotificationPolicyRouteKind).AsList())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := alertmanagerConfig{
+		Route: alertmanagerRouteFromPolicy(tree),
+	}
+
+	receivers, receiverWarnings, err := alertmanagerReceivers(resources.OfKind(AlertContactPointKind).AsList(), opts.ResolveSecrets)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.Receivers = receivers
+	warnings = append(warnings, receiverWarnings...)
+
+	exported := map[string]bool{}
+	for _, receiver := range receivers {
+		exported[receiver.Name] = true
+	}
+	for _, name := range routeReceivers(config.Route) {
+		if !exported[name] {
+			warnings = append(warnings, fmt.Sprintf("receiver %s of the notification policy has no contact point", name))
+		}
+	}
+
+	for _, muteTiming := range sortedByName(resources.OfKind(KindAlertMuteTiming).AsList()) {
+		config.TimeIntervals = append(config.TimeIntervals, map[string]any{
+			"name":           muteTiming.Name(),
+			"time_intervals": muteTiming.GetSpecValue("time_intervals"),
+		})
+	}
+
+	templates := resources.OfKind(KindAlertNotificationTemplate).AsList()
+	if len(templates) != 0 {
+		config.Templates = []string{path.Join(alertmanagerTemplatesDir, "*.tmpl")}
+	}
+	for _, template := range templates {
+		content, _ := template.GetSpecValue("template").(string)
+		files[path.Join(alertmanagerTemplatesDir, template.Name()+".tmpl")] = []byte(content)
+	}
+
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	files[alertmanagerConfigFile] = content
+
+	return files, warnings, nil
+}
+
+// mergedPolicyTree returns the route tree of a notification policy, with the
+// subtrees of AlertNotificationPolicyRoute resources merged the way they are
+// applied.
+func mergedPolicyTree(policy grizzly.Resource, routes []grizzly.Resource) (*models.Route, error) {
+	var tree models.Route
+	if err := unmarshalSpec(policy, &tree); err != nil {
+		return nil, err
+	}
+
+	for _, resource := range sortedByName(routes) {
+		var route models.Route
+		if err := unmarshalSpec(resource, &route); err != nil {
+			return nil, err
+		}
+		if err := mergePolicyRoute(&tree, &route); err != nil {
+			return nil, err
+		}
+	}
+	return &tree, nil
+}
+
+func alertmanagerRouteFromPolicy(route *models.Route) *alertmanagerRoute {
+	converted := &alertmanagerRoute{
+		Receiver:            route.Receiver,
+		GroupBy:             route.GroupBy,
+		Continue:            route.Continue,
+		GroupWait:           route.GroupWait,
+		GroupInterval:       route.GroupInterval,
+		RepeatInterval:      route.RepeatInterval,
+		MuteTimeIntervals:   route.MuteTimeIntervals,
+		ActiveTimeIntervals: route.ActiveTimeIntervals,
+	}
+
+	for _, name := range sortedKeys(route.Match) {
+		converted.Matchers = append(converted.Matchers, alertmanagerMatcher(name, "=", route.Match[name]))
+	}
+	for _, name := range sortedKeys(route.MatchRe) {
+		converted.Matchers = append(converted.Matchers, alertmanagerMatcher(name, "=~", route.MatchRe[name]))
+	}
+	for _, matcher := range route.Matchers {
+		if matcher == nil || matcher.Name == nil || matcher.Value == nil {
+			continue
+		}
+		operator := map[[2]bool]string{{true, false}: "=", {false, false}: "!=", {true, true}: "=~", {false, true}: "!~"}[[2]bool{matcher.IsEqual, matcher.IsRegex != nil && *matcher.IsRegex}]
+		converted.Matchers = append(converted.Matchers, alertmanagerMatcher(*matcher.Name, operator, *matcher.Value))
+	}
+	for _, matcher := range route.ObjectMatchers {
+		if len(matcher) == 3 {
+			converted.Matchers = append(converted.Matchers, alertmanagerMatcher(matcher[0], matcher[1], matcher[2]))
+		}
+	}
+
+	for _, nested := range route.Routes {
+		if nested != nil {
+			converted.Routes = append(converted.Routes, alertmanagerRouteFromPolicy(nested))
+		}
+	}
+	return converted
+}
+
+func alertmanagerMatcher(name, operator, value string) string {
+	return name + operator + strconv.Quote(value)
+}
+
+func alertmanagerReceivers(contactPoints []grizzly.Resource, resolveSecrets bool) ([]alertmanagerReceiver, []string, error) {
+	var warnings []string
+	receivers := map[string]*alertmanagerReceiver{}
+	var names []string
+
+	for _, contactPoint := range sortedByName(contactPoints) {
+		if resolveSecrets {
+			resolved, err := grizzly.ResolveSecrets(contactPoint)
+			if err != nil {
+				return nil, nil, err
+			}
+			contactPoint = resolved
+		}
+
+		name, _ := contactPoint.GetSpecString("name")
+		receiver, found := receivers[name]
+		if !found {
+			receiver = &alertmanagerReceiver{Name: name, Configs: map[string][]any{}}
+			receivers[name] = receiver
+			names = append(names, name)
+		}
+
+		integrationType, _ := contactPoint.GetSpecString("type")
+		integration, supported := alertmanagerIntegrations[integrationType]
+		if !supported {
+			warnings = append(warnings, fmt.Sprintf("contact point %s: %s integrations have no Alertmanager equivalent, skipped", contactPoint.Name(), integrationType))
+			continue
+		}
+
+		values, _ := contactPoint.GetSpecValue("settings").(map[string]any)
+		settings, leftOut := alertmanagerSettings(contactPoint, values)
+		if len(leftOut) != 0 {
+			warnings = append(warnings, fmt.Sprintf("contact point %s: secrets of settings %s aren't read from files, left out", contactPoint.Name(), strings.Join(leftOut, ", ")))
+		}
+		config, err := integration.convert(settings)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("contact point %s: %s, skipped", contactPoint.Name(), err))
+			continue
+		}
+		if unused := settings.unused(); len(unused) != 0 {
+			warnings = append(warnings, fmt.Sprintf("contact point %s: settings %s have no Alertmanager equivalent", contactPoint.Name(), strings.Join(unused, ", ")))
+		}
+
+		disableResolveMessage, _ := contactPoint.GetSpecValue("disableResolveMessage").(bool)
+		config["send_resolved"] = !disableResolveMessage
+		receiver.Configs[integration.key] = append(receiver.Configs[integration.key], config)
+	}
+
+	sort.Strings(names)
+	result := make([]alertmanagerReceiver, 0, len(names))
+	for _, name := range names {
+		result = append(result, *receivers[name])
+	}
+	return result, warnings, nil
+}
+
+// alertmanagerSettings separates the settings holding references to secret
+// files from the others. It returns the names of the settings holding other
+// secret references, which are left out.
+func alertmanagerSettings(contactPoint grizzly.Resource, values map[string]any) (*integrationSettings, []string) {
+	settings := &integrationSettings{values: map[string]any{}, files: map[string]string{}, used: map[string]bool{}}
+	var leftOut []string
+	for key, value := range values {
+		if !containsSecretReference(value) {
+			settings.values[key] = value
+			continue
+		}
+		item, _ := value.(map[string]any)
+		reference, _ := item[grizzly.SecretKey].(map[string]any)
+		file, isFile := reference["file"].(string)
+		if !isFile {
+			leftOut = append(leftOut, key)
+			continue
+		}
+		if !filepath.IsAbs(file) && contactPoint.Source.Path != "" {
+			file = filepath.Join(filepath.Dir(contactPoint.Source.Path), file)
+		}
+		settings.files[key] = file
+	}
+	sort.Strings(leftOut)
+	return settings, leftOut
+}
+
+func routeReceivers(route *alertmanagerRoute) []string {
+	var names []string
+	if route.Receiver != "" {
+		names = append(names, route.Receiver)
+	}
+	for _, nested := range route.Routes {
+		names = append(names, routeReceivers(nested)...)
+	}
+	return names
+}
@@ -0,0 +1,163 @@
+package grafana
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportAlertmanagerConfig(t *testing.T) {
+	resource := func(kind, name string, spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", kind, name, spec)
+		require.NoError(t, err)
+		return resource
+	}
+
+	resources := grizzly.NewResources(
+		resource(AlertNotificationPolicyKind, "global", map[string]any{
+			"receiver": "default",
+			"group_by": []any{"alertname"},
+			"routes": []any{
+				map[string]any{
+					"receiver":            "oncall",
+					"object_matchers":     []any{[]any{"severity", "=", "critical"}},
+					"mute_time_intervals": []any{"weekends"},
+				},
+				map[string]any{"receiver": "legacy", "object_matchers": []any{[]any{"team", "=", "platform"}}},
+			},
+		}),
+		resource(AlertNotificationPolicyRouteKind, "team=platform", map[string]any{
+			"receiver":        "platform",
+			"object_matchers": []any{[]any{"team", "=", "platform"}},
+			"routes": []any{
+				map[string]any{"receiver": "unknown", "object_matchers": []any{[]any{"service", "=~", "api|web"}}},
+			},
+		}),
+		resource(AlertContactPointKind, "default-email", map[string]any{
+			"name":     "default",
+			"type":     "email",
+			"settings": map[string]any{"addresses": "a@example.com;b@example.com", "singleEmail": true},
+		}),
+		resource(AlertContactPointKind, "oncall-pagerduty", map[string]any{
+			"name":                  "oncall",
+			"type":                  "pagerduty",
+			"disableResolveMessage": true,
+			"settings":              map[string]any{"integrationKey": "secret", "severity": "critical"},
+		}),
+		resource(AlertContactPointKind, "oncall-oncall", map[string]any{
+			"name":     "oncall",
+			"type":     "oncall",
+			"settings": map[string]any{"url": "https://oncall.example.com"},
+		}),
+		resource(AlertContactPointKind, "platform-slack", map[string]any{
+			"name":     "platform",
+			"type":     "slack",
+			"settings": map[string]any{"url": "https://hooks.slack.com/services/x", "recipient": "#platform"},
+		}),
+		resource(KindAlertMuteTiming, "weekends", map[string]any{
+			"name":           "weekends",
+			"time_intervals": []any{map[string]any{"weekdays": []any{"saturday", "sunday"}}},
+		}),
+		resource(KindAlertNotificationTemplate, "slack", map[string]any{
+			"name":     "slack",
+			"template": `{{ define "slack.title" }}{{ .Status }}{{ end }}`,
+		}),
+	)
+
+	files, warnings, err := ExportAlertmanagerConfig(resources, AlertmanagerOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"contact point default-email: settings singleEmail have no Alertmanager equivalent",
+		"contact point oncall-oncall: oncall integrations have no Alertmanager equivalent, skipped",
+		"receiver unknown of the notification policy has no contact point",
+	}, warnings)
+	require.Equal(t, `{{ define "slack.title" }}{{ .Status }}{{ end }}`, string(files["templates/slack.tmpl"]))
+
+	var config map[string]any
+	require.NoError(t, yaml.Unmarshal(files["alertmanager.yml"], &config))
+	require.Equal(t, []any{"templates/*.tmpl"}, config["templates"])
+	require.Equal(t, []any{map[string]any{
+		"name":           "weekends",
+		"time_intervals": []any{map[string]any{"weekdays": []any{"saturday", "sunday"}}},
+	}}, config["time_intervals"])
+
+	require.Equal(t, map[string]any{
+		"receiver": "default",
+		"group_by": []any{"alertname"},
+		"routes": []any{
+			map[string]any{
+				"receiver":            "oncall",
+				"matchers":            []any{`severity="critical"`},
+				"mute_time_intervals": []any{"weekends"},
+			},
+			map[string]any{
+				"receiver": "platform",
+				"matchers": []any{`team="platform"`},
+				"routes": []any{
+					map[string]any{"receiver": "unknown", "matchers": []any{`service=~"api|web"`}},
+				},
+			},
+		},
+	}, config["route"])
+
+	require.Equal(t, []any{
+		map[string]any{
+			"name":          "default",
+			"email_configs": []any{map[string]any{"to": "a@example.com, b@example.com", "send_resolved": true}},
+		},
+		map[string]any{
+			"name":              "oncall",
+			"pagerduty_configs": []any{map[string]any{"routing_key": "secret", "severity": "critical", "send_resolved": false}},
+		},
+		map[string]any{
+			"name":          "platform",
+			"slack_configs": []any{map[string]any{"api_url": "https://hooks.slack.com/services/x", "channel": "#platform", "send_resolved": true}},
+		},
+	}, config["receivers"])
+
+	t.Run("a notification policy is required", func(t *testing.T) {
+		_, _, err := ExportAlertmanagerConfig(resources.OfKind(AlertContactPointKind), AlertmanagerOptions{})
+		require.ErrorContains(t, err, "exactly one AlertNotificationPolicy is required")
+	})
+
+	t.Run("secrets are only written in clear on demand", func(t *testing.T) {
+		t.Setenv("SLACK_TOKEN", "from-env")
+		passwordFile := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(passwordFile, []byte("from-file"), 0600))
+		withSecrets := grizzly.NewResources(
+			resources.OfKind(AlertNotificationPolicyKind).First(),
+			resource(AlertContactPointKind, "platform-slack", map[string]any{
+				"name": "platform",
+				"type": "slack",
+				"settings": map[string]any{
+					"token":     map[string]any{grizzly.SecretKey: map[string]any{"env": "SLACK_TOKEN"}},
+					"recipient": "#platform",
+				},
+			}),
+			resource(AlertContactPointKind, "oncall-webhook", map[string]any{
+				"name": "oncall",
+				"type": "webhook",
+				"settings": map[string]any{
+					"url":      "https://oncall.example.com",
+					"username": "grafana",
+					"password": map[string]any{grizzly.SecretKey: map[string]any{"file": passwordFile}},
+				},
+			}),
+		)
+
+		files, warnings, err := ExportAlertmanagerConfig(withSecrets, AlertmanagerOptions{})
+		require.NoError(t, err)
+		require.Contains(t, warnings, "contact point platform-slack: secrets of settings token aren't read from files, left out")
+		require.NotContains(t, string(files["alertmanager.yml"]), "from-env")
+		require.Contains(t, string(files["alertmanager.yml"]), "password_file: "+passwordFile)
+
+		files, _, err = ExportAlertmanagerConfig(withSecrets, AlertmanagerOptions{ResolveSecrets: true})
+		require.NoError(t, err)
+		require.Contains(t, string(files["alertmanager.yml"]), "credentials: from-env")
+		require.Contains(t, string(files["alertmanager.yml"]), "password: from-file")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/grafana/grafana-openapi-client-go/client/dashboards"
@@ -21,6 +22,7 @@ const DashboardKind = "Dashboard"
 
 var _ grizzly.Handler = &DashboardHandler{}
 var _ grizzly.ProxyConfiguratorProvider = &DashboardHandler{}
+var _ grizzly.HierarchicalHandler = &DashboardHandler{}
 
 // DashboardHandler is a Grizzly Handler for Grafana dashboards
 type DashboardHandler struct {
@@ -35,7 +37,8 @@ func NewDashboardHandler(provider grizzly.Provider) *DashboardHandler {
 }
 
 const (
-	dashboardPattern = "dashboards/%s/dashboard-%s.%s"
+	dashboardPattern             = "dashboards/%s/dashboard-%s.%s"
+	hierarchicalDashboardPattern = "dashboard-%s.%s"
 )
 
 // ProxyConfigurator provides a configurator object describing how to proxy dashboards.
@@ -50,6 +53,17 @@ func (h *DashboardHandler) ResourceFilePath(resource grizzly.Resource, filetype
 	return fmt.Sprintf(dashboardPattern, resource.GetMetadata("folder"), resource.Name(), filetype)
 }
 
+// HierarchicalFilePath returns the location on disk where a dashboard should be
+// written when mirroring the folder hierarchy: in the directory named after its
+// folder title, itself nested in the directories of the folder's parents
+func (h *DashboardHandler) HierarchicalFilePath(resource grizzly.Resource, filetype string) (string, error) {
+	dir, err := NewFolderHandler(h.Provider).folderTitlePath(resource.GetMetadata("folder"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf(hierarchicalDashboardPattern, resource.Name(), filetype)), nil
+}
+
 // Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
 func (h *DashboardHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
 	resource.DeleteSpecKey("id")
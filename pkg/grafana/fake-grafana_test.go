@@ -0,0 +1,61 @@
+package grafana
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeGrafana serves the routes of the Grafana API a handler is tested
+// against. Routes are ServeMux patterns, e.g. "GET /api/teams/{id}". Requests
+// to any other route fail the test.
+// Route handlers run on the goroutines of the server: they must report
+// failures with assert, never with require.
+func newFakeGrafana(t *testing.T, routes map[string]http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotImplemented)
+	})
+	for pattern, handler := range routes {
+		mux.HandleFunc(pattern, handler)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// readJSON decodes the body of a request, reporting a failure if it can't
+func readJSON(t *testing.T, r *http.Request, v any) bool {
+	return assert.NoError(t, json.NewDecoder(r.Body).Decode(v))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{}`))
+}
+
+// applyResources applies resources, and returns the summary of the events
+func applyResources(t *testing.T, registry grizzly.Registry, resources ...grizzly.Resource) grizzly.Summary {
+	t.Helper()
+	recorder := grizzly.NewWriterRecorder(io.Discard, grizzly.EventToPlainText)
+	require.NoError(t, grizzly.Apply(registry, grizzly.NewResources(resources...), false, recorder))
+	return recorder.Summary()
+}
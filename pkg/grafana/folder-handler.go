@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	gclient "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-openapi-client-go/client/folders"
@@ -18,12 +21,21 @@ const DashboardFolderKind = "DashboardFolder"
 
 var _ grizzly.Handler = &FolderHandler{}
 var _ grizzly.ProxyConfiguratorProvider = &FolderHandler{}
+var _ grizzly.HierarchicalHandler = &FolderHandler{}
 
 // FolderHandler is a Grizzly Handler for Grafana dashboard folders
 type FolderHandler struct {
 	grizzly.BaseHandler
 }
 
+// folderTitlePaths caches the title paths of remote folders by UID, so that
+// each folder is fetched once when pulling its dashboards. It is kept by the
+// provider, for all the handlers of folders and dashboards to share it.
+type folderTitlePaths struct {
+	mu    sync.Mutex
+	paths map[string]string
+}
+
 // NewFolderHandler returns configuration defining a new Grafana Folder Handler
 func NewFolderHandler(provider grizzly.Provider) *FolderHandler {
 	return &FolderHandler{
@@ -32,7 +44,8 @@ func NewFolderHandler(provider grizzly.Provider) *FolderHandler {
 }
 
 const (
-	folderPattern = "folders/folder-%s.%s"
+	folderPattern             = "folders/folder-%s.%s"
+	hierarchicalFolderPattern = "folder-%s.%s"
 )
 
 // ProxyConfigurator provides a configurator object describing how to proxy folders.
@@ -47,6 +60,17 @@ func (h *FolderHandler) ResourceFilePath(resource grizzly.Resource, filetype str
 	return fmt.Sprintf(folderPattern, resource.Name(), filetype)
 }
 
+// HierarchicalFilePath returns the location on disk where a folder should be
+// written when mirroring the folder hierarchy: within a directory named after
+// its title, itself nested in the directories of its parents
+func (h *FolderHandler) HierarchicalFilePath(resource grizzly.Resource, filetype string) (string, error) {
+	dir, err := h.folderTitlePath(resource.Name())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf(hierarchicalFolderPattern, resource.Name(), filetype)), nil
+}
+
 // Prepare gets a resource ready for dispatch to the remote endpoint
 func (h *FolderHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
 	if !resource.HasSpecString("uid") {
@@ -146,11 +170,13 @@ func (h *FolderHandler) ListRemote() ([]string, error) {
 
 // Add pushes a new folder to Grafana via the API
 func (h *FolderHandler) Add(resource grizzly.Resource) error {
+	h.forgetTitlePaths()
 	return h.postFolder(resource)
 }
 
 // Update pushes a folder to Grafana via the API
 func (h *FolderHandler) Update(existing, resource grizzly.Resource) error {
+	h.forgetTitlePaths()
 	return h.putFolder(resource)
 }
 
@@ -198,6 +224,77 @@ func (h *FolderHandler) getRemoteFolder(uid string) (*grizzly.Resource, error) {
 	return &resource, nil
 }
 
+// folderTitlePath builds a path mirroring the titles of a folder and of all
+// its parents, starting from the root. The General folder maps to an empty path.
+// The paths of the folder and of its parents are cached.
+func (h *FolderHandler) folderTitlePath(uid string) (string, error) {
+	if uid == "" || uid == DefaultFolder || uid == strings.ToLower(DefaultFolder) {
+		return "", nil
+	}
+
+	cache := h.titlePaths()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if path, ok := cache.paths[uid]; ok {
+		return path, nil
+	}
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return "", err
+	}
+
+	folderOk, err := client.Folders.GetFolderByUID(uid)
+	if err != nil {
+		var gErrNotFound *folders.GetFolderByUIDNotFound
+		var gErrForbidden *folders.GetFolderByUIDForbidden
+		if errors.As(err, &gErrNotFound) || errors.As(err, &gErrForbidden) {
+			return "", fmt.Errorf("couldn't fetch folder '%s' from remote: %w", uid, grizzly.ErrNotFound)
+		}
+		return "", err
+	}
+	folder := folderOk.GetPayload()
+
+	if cache.paths == nil {
+		cache.paths = map[string]string{}
+	}
+	parts := make([]string, 0, len(folder.Parents)+1)
+	for _, parent := range folder.Parents {
+		parts = append(parts, folderTitleToDirectory(parent.Title, parent.UID))
+		cache.paths[parent.UID] = filepath.Join(parts...)
+	}
+	parts = append(parts, folderTitleToDirectory(folder.Title, folder.UID))
+	cache.paths[uid] = filepath.Join(parts...)
+
+	return cache.paths[uid], nil
+}
+
+// titlePaths returns the cache of title paths of the provider
+func (h *FolderHandler) titlePaths() *folderTitlePaths {
+	if provider, ok := h.Provider.(*Provider); ok {
+		return &provider.folderTitlePaths
+	}
+	return &folderTitlePaths{}
+}
+
+// forgetTitlePaths empties the cache of title paths, as folders are changed
+func (h *FolderHandler) forgetTitlePaths() {
+	cache := h.titlePaths()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.paths = nil
+}
+
+// folderTitleToDirectory turns a folder title into a usable directory name,
+// falling back to the folder UID when the title can't be used as-is.
+func folderTitleToDirectory(title string, uid string) string {
+	name := strings.ReplaceAll(strings.TrimSpace(title), string(os.PathSeparator), "-")
+	if name == "" || name == "." || name == ".." {
+		return uid
+	}
+	return name
+}
+
 func (h *FolderHandler) getRemoteFolderList() ([]string, error) {
 	var (
 		limit            = int64(1000)
@@ -1,8 +1,12 @@
 package grafana
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/grafana/grizzly/pkg/config"
 	"github.com/grafana/grizzly/pkg/grizzly"
 	"github.com/stretchr/testify/require"
 )
@@ -95,3 +99,78 @@ func TestSortFolders(t *testing.T) {
 		})
 	}
 }
+
+func TestHierarchicalFilePath(t *testing.T) {
+	remoteFolders := map[string]string{
+		"root":  `{"uid": "root", "title": "Platform", "parents": []}`,
+		"child": `{"uid": "child", "title": "Team / Alerts", "parentUid": "root", "parents": [{"uid": "root", "title": "Platform"}]}`,
+	}
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		body, ok := remoteFolders[strings.TrimPrefix(r.URL.Path, "/api/folders/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+	folderHandler := NewFolderHandler(provider)
+	dashboardHandler := NewDashboardHandler(provider)
+
+	dashboard := func(uid string, folderUID string) grizzly.Resource {
+		resource, _ := grizzly.NewResource(dashboardHandler.APIVersion(), dashboardHandler.Kind(), uid, map[string]interface{}{"uid": uid})
+		resource.SetMetadata("folder", folderUID)
+		return resource
+	}
+
+	t.Run("folders are written in a directory named after their title", func(t *testing.T) {
+		resource, _ := grizzly.NewResource(folderHandler.APIVersion(), folderHandler.Kind(), "child", map[string]interface{}{"uid": "child"})
+
+		path, err := folderHandler.HierarchicalFilePath(resource, "yaml")
+		require.NoError(t, err)
+		require.Equal(t, "Platform/Team - Alerts/folder-child.yaml", path)
+	})
+
+	t.Run("dashboards are written in their folder's directory", func(t *testing.T) {
+		path, err := dashboardHandler.HierarchicalFilePath(dashboard("dash", "child"), "json")
+		require.NoError(t, err)
+		require.Equal(t, "Platform/Team - Alerts/dashboard-dash.json", path)
+	})
+
+	t.Run("folders are fetched once", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+		clear(requests)
+		for _, uid := range []string{"first", "second"} {
+			_, err := NewDashboardHandler(provider).HierarchicalFilePath(dashboard(uid, "child"), "json")
+			require.NoError(t, err)
+		}
+		require.Equal(t, 1, requests["/api/folders/child"])
+
+		path, err := NewDashboardHandler(provider).HierarchicalFilePath(dashboard("dash", "root"), "json")
+		require.NoError(t, err)
+		require.Equal(t, "Platform/dashboard-dash.json", path)
+		require.Zero(t, requests["/api/folders/root"])
+
+		// changing folders through any handler empties the cache of all
+		NewFolderHandler(provider).forgetTitlePaths()
+		_, err = NewDashboardHandler(provider).HierarchicalFilePath(dashboard("dash", "child"), "json")
+		require.NoError(t, err)
+		require.Equal(t, 2, requests["/api/folders/child"])
+	})
+
+	t.Run("dashboards in the general folder are written at the root", func(t *testing.T) {
+		path, err := dashboardHandler.HierarchicalFilePath(dashboard("dash", generalFolderUID), "yaml")
+		require.NoError(t, err)
+		require.Equal(t, "dashboard-dash.yaml", path)
+	})
+
+	t.Run("unknown folders are reported", func(t *testing.T) {
+		_, err := dashboardHandler.HierarchicalFilePath(dashboard("dash", "unknown"), "yaml")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+}
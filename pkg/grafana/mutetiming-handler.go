@@ -0,0 +1,217 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const KindAlertMuteTiming = "AlertMuteTiming"
+
+const muteTimingPattern = "alert-mute-timings/muteTiming-%s.%s"
+
+var _ grizzly.Handler = &AlertMuteTimingHandler{}
+var _ grizzly.DeleteHandler = &AlertMuteTimingHandler{}
+
+// AlertMuteTimingHandler is a Grizzly Handler for Grafana mute timings
+type AlertMuteTimingHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewAlertMuteTimingHandler returns a new Grizzly Handler for Grafana mute timings
+func NewAlertMuteTimingHandler(provider grizzly.Provider) *AlertMuteTimingHandler {
+	return &AlertMuteTimingHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, KindAlertMuteTiming, false),
+	}
+}
+
+// ProxyConfigurator provides a configurator object describing how to proxy mute timings.
+func (h *AlertMuteTimingHandler) ProxyConfigurator() grizzly.ProxyConfigurator {
+	return &alertMuteTimingProxyConfigurator{
+		provider: h.Provider,
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *AlertMuteTimingHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(muteTimingPattern, filename, filetype)
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *AlertMuteTimingHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("name") {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *AlertMuteTimingHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	resource.DeleteSpecKey("version")
+	resource.DeleteSpecKey("provenance")
+	return &resource
+}
+
+func (h *AlertMuteTimingHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if resource.Name() != name && exist {
+		return fmt.Errorf("spec.name '%s' and metadata.name '%s', don't match", name, resource.Name())
+	}
+	return nil
+}
+
+func (h *AlertMuteTimingHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, ok := resource.GetSpecString("name")
+	if !ok {
+		return "", fmt.Errorf("name not specified")
+	}
+	return name, nil
+}
+
+// GetByUID retrieves a mute timing by name
+func (h *AlertMuteTimingHandler) GetByUID(name string) (*grizzly.Resource, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Provisioning.GetMuteTiming(name)
+	if err != nil {
+		var gErr *provisioning.GetMuteTimingNotFound
+		if errors.As(err, &gErr) {
+			return nil, grizzly.ErrNotFound
+		}
+		return nil, err
+	}
+
+	spec, err := structToMap(response.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+	removeEmptyTimeIntervalFields(spec)
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), name, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+// GetRemote retrieves a mute timing as a Resource
+func (h *AlertMuteTimingHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// ListRemote retrieves a list of sorted names of all remote mute timings
+func (h *AlertMuteTimingHandler) ListRemote() ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Provisioning.GetMuteTimings()
+	if err != nil {
+		return nil, err
+	}
+	muteTimings := response.GetPayload()
+	names := make([]string, 0, len(muteTimings))
+	for _, muteTiming := range muteTimings {
+		names = append(names, muteTiming.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Add pushes a mute timing to Grafana via the API
+func (h *AlertMuteTimingHandler) Add(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	muteTiming, err := h.muteTiming(resource)
+	if err != nil {
+		return err
+	}
+
+	params := provisioning.NewPostMuteTimingParams().
+		WithBody(muteTiming).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PostMuteTiming(params)
+	return err
+}
+
+// Update pushes a mute timing to Grafana via the API
+func (h *AlertMuteTimingHandler) Update(existing, resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	muteTiming, err := h.muteTiming(resource)
+	if err != nil {
+		return err
+	}
+
+	params := provisioning.NewPutMuteTimingParams().
+		WithName(existing.Name()).
+		WithBody(muteTiming).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PutMuteTiming(params)
+	return err
+}
+
+// Delete deletes a mute timing via the API
+func (h *AlertMuteTimingHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	params := provisioning.NewDeleteMuteTimingParams().
+		WithName(resource.Name()).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.DeleteMuteTiming(params)
+	return err
+}
+
+func (h *AlertMuteTimingHandler) muteTiming(resource grizzly.Resource) (*models.MuteTimeInterval, error) {
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return nil, err
+	}
+
+	var muteTiming models.MuteTimeInterval
+	if err := json.Unmarshal(data, &muteTiming); err != nil {
+		return nil, err
+	}
+	muteTiming.Name = resource.Name()
+
+	return &muteTiming, nil
+}
+
+// removeEmptyTimeIntervalFields removes the fields of time intervals the API
+// client returns as null, when they aren't set.
+func removeEmptyTimeIntervalFields(spec map[string]any) {
+	intervals, _ := spec["time_intervals"].([]any)
+	for _, interval := range intervals {
+		fields, ok := interval.(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, value := range fields {
+			if value == nil {
+				delete(fields, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package grafana
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertMuteTimingHandler(t *testing.T) {
+	muteTimings := map[string]map[string]any{}
+
+	server := newFakeGrafana(t, map[string]http.HandlerFunc{
+		"GET /api/v1/provisioning/mute-timings": func(w http.ResponseWriter, r *http.Request) {
+			list := []map[string]any{}
+			for _, muteTiming := range muteTimings {
+				list = append(list, muteTiming)
+			}
+			writeJSON(w, list)
+		},
+		"POST /api/v1/provisioning/mute-timings": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "true", r.Header.Get("X-Disable-Provenance"))
+			var body map[string]any
+			if !readJSON(t, r, &body) {
+				return
+			}
+			muteTimings[body["name"].(string)] = body
+			w.WriteHeader(http.StatusCreated)
+			writeJSON(w, body)
+		},
+		"GET /api/v1/provisioning/mute-timings/{name}": func(w http.ResponseWriter, r *http.Request) {
+			if muteTimings[r.PathValue("name")] == nil {
+				writeNotFound(w)
+				return
+			}
+			// the API sends unset fields as null
+			muteTiming := map[string]any{"version": "abc", "provenance": ""}
+			for key, value := range muteTimings[r.PathValue("name")] {
+				muteTiming[key] = value
+			}
+			writeJSON(w, muteTiming)
+		},
+		"PUT /api/v1/provisioning/mute-timings/{name}": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			if !readJSON(t, r, &body) {
+				return
+			}
+			muteTimings[r.PathValue("name")] = body
+			w.WriteHeader(http.StatusAccepted)
+			writeJSON(w, body)
+		},
+	})
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewAlertMuteTimingHandler(provider)
+
+	resource, err := grizzly.NewResource(provider.APIVersion(), KindAlertMuteTiming, "weekends", map[string]any{
+		"name": "weekends",
+		"time_intervals": []any{
+			map[string]any{"weekdays": []any{"saturday", "sunday"}},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("mute timings are created", func(t *testing.T) {
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceAdded])
+		require.Contains(t, muteTimings, "weekends")
+	})
+
+	t.Run("mute timings are compared without unset fields", func(t *testing.T) {
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceNotChanged])
+	})
+
+	t.Run("mute timings are updated", func(t *testing.T) {
+		resource.SetSpecValue("time_intervals", []any{
+			map[string]any{"weekdays": []any{"saturday", "sunday"}, "location": "Europe/Paris"},
+		})
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, "Europe/Paris", muteTimings["weekends"]["time_intervals"].([]any)[0].(map[string]any)["location"])
+	})
+
+	t.Run("mute timings are listed and pulled", func(t *testing.T) {
+		names, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"weekends"}, names)
+
+		remote, err := handler.GetByUID("weekends")
+		require.NoError(t, err)
+		require.Equal(t, []any{
+			map[string]any{"weekdays": []any{"saturday", "sunday"}, "location": "Europe/Paris"},
+		}, remote.GetSpecValue("time_intervals"))
+
+		_, err = handler.GetByUID("holidays")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+}
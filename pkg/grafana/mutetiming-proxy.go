@@ -0,0 +1,157 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi"
+	"github.com/grafana/grizzly/internal/httputils"
+	"github.com/grafana/grizzly/internal/utils"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+var _ grizzly.ProxyConfigurator = &alertMuteTimingProxyConfigurator{}
+
+// alertMuteTimingProxyConfigurator describes how to proxy AlertMuteTiming resources.
+type alertMuteTimingProxyConfigurator struct {
+	provider grizzly.Provider
+}
+
+// ProxyURL returns the URL to use to view a mute timing via the proxy.
+func (c *alertMuteTimingProxyConfigurator) ProxyURL(name string) string {
+	return fmt.Sprintf("/alerting/routes/mute-timing/edit?muteName=%s", url.QueryEscape(name))
+}
+
+// Endpoints lists HTTP handlers to register on the proxy.
+// The alert manager config endpoints, also used by the mute timings UI, are
+// registered by the notification templates proxy.
+func (c *alertMuteTimingProxyConfigurator) Endpoints(s grizzly.Server) []grizzly.HTTPEndpoint {
+	return []grizzly.HTTPEndpoint{
+		{
+			Method:  http.MethodGet,
+			URL:     "/alerting/routes/mute-timing/edit",
+			Handler: authenticateAndProxyHandler(s, c.provider),
+		},
+		// Depending on the Grafana version, the frontend might call k8s-style endpoints
+		{
+			Method:  http.MethodGet,
+			URL:     "/apis/notifications.alerting.grafana.app/v0alpha1/namespaces/{namespace}/timeintervals",
+			Handler: c.listAsK8S(s),
+		},
+		{
+			Method:  http.MethodGet,
+			URL:     "/apis/notifications.alerting.grafana.app/v0alpha1/namespaces/{namespace}/timeintervals/{name}",
+			Handler: c.getAsK8S(s),
+		},
+		{
+			Method:  http.MethodPut,
+			URL:     "/apis/notifications.alerting.grafana.app/v0alpha1/namespaces/{namespace}/timeintervals/{name}",
+			Handler: c.saveAsK8S(s),
+		},
+	}
+}
+
+// StaticEndpoints lists endpoints to be proxied transparently.
+func (c *alertMuteTimingProxyConfigurator) StaticEndpoints() grizzly.StaticProxyConfig {
+	return grizzly.StaticProxyConfig{}
+}
+
+func (c *alertMuteTimingProxyConfigurator) listAsK8S(s grizzly.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		muteTimings := s.Resources.OfKind(KindAlertMuteTiming).AsList()
+		muteTimingsAsK8S := utils.Map(muteTimings, func(muteTiming grizzly.Resource) map[string]any {
+			return c.resourceAsK8S(chi.URLParam(r, "namespace"), muteTiming)
+		})
+
+		httputils.WriteJSON(w, map[string]any{
+			"kind":       "TimeIntervalList",
+			"apiVersion": "notifications.alerting.grafana.app/v0alpha1",
+			"metadata":   map[string]any{},
+			"items":      muteTimingsAsK8S,
+		})
+	}
+}
+
+func (c *alertMuteTimingProxyConfigurator) getAsK8S(s grizzly.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+
+		muteTiming, found := s.Resources.Find(grizzly.NewResourceRef(KindAlertMuteTiming, name))
+		if !found {
+			c.httpNotFound(w, name)
+			return
+		}
+
+		httputils.WriteJSON(w, c.resourceAsK8S(chi.URLParam(r, "namespace"), muteTiming))
+	}
+}
+
+func (c *alertMuteTimingProxyConfigurator) saveAsK8S(s grizzly.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+
+		muteTiming, found := s.Resources.Find(grizzly.NewResourceRef(KindAlertMuteTiming, name))
+		if !found {
+			c.httpNotFound(w, name)
+			return
+		}
+
+		input := &struct {
+			Spec struct {
+				TimeIntervals []any `json:"time_intervals"`
+			} `json:"spec"`
+		}{}
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			httputils.Error(w, "could not read request body", err, http.StatusInternalServerError)
+			return
+		}
+		if err := json.Unmarshal(content, input); err != nil {
+			httputils.Error(w, "Error parsing request", err, http.StatusBadRequest)
+			return
+		}
+
+		muteTiming.SetSpecValue("time_intervals", input.Spec.TimeIntervals)
+
+		if err := s.UpdateResource(muteTiming); err != nil {
+			httputils.Error(w, err.Error(), err, http.StatusInternalServerError)
+			return
+		}
+
+		httputils.WriteJSON(w, c.resourceAsK8S(chi.URLParam(r, "namespace"), muteTiming))
+	}
+}
+
+func (c *alertMuteTimingProxyConfigurator) httpNotFound(w http.ResponseWriter, name string) {
+	httputils.Error(w, fmt.Sprintf("Mute timing %s not found", name), fmt.Errorf("mute timing %s not found", name), http.StatusNotFound)
+}
+
+func (c *alertMuteTimingProxyConfigurator) resourceAsK8S(namespace string, resource grizzly.Resource) map[string]any {
+	return map[string]any{
+		"kind":       "TimeInterval",
+		"apiVersion": "notifications.alerting.grafana.app/v0alpha1",
+		"metadata": map[string]any{
+			"name":            resource.Name(),
+			"uid":             resource.Name(),
+			"namespace":       namespace,
+			"resourceVersion": "resource-version",
+		},
+		"spec": muteTimingAsConfig(resource),
+	}
+}
+
+// muteTimingAsConfig returns a mute timing the way the alert manager config
+// describes it.
+func muteTimingAsConfig(resource grizzly.Resource) map[string]any {
+	timeIntervals := resource.GetSpecValue("time_intervals")
+	if timeIntervals == nil {
+		timeIntervals = []any{}
+	}
+	return map[string]any{
+		"name":           resource.Name(),
+		"time_intervals": timeIntervals,
+	}
+}
@@ -16,7 +16,10 @@ const (
 
 var _ grizzly.Handler = &AlertNotificationPolicyHandler{}
 
-// AlertNotificationPolicyHandler is a Grizzly Handler for Grafana alertNotificationPolicies
+// AlertNotificationPolicyHandler is a Grizzly Handler for Grafana alertNotificationPolicies.
+// The policy owns the whole tree, unless grafana.notification-policy-routes is
+// set: top-level routes with object_matchers it doesn't list are then owned by
+// AlertNotificationPolicyRoute resources, and left untouched.
 type AlertNotificationPolicyHandler struct {
 	grizzly.BaseHandler
 }
@@ -49,14 +52,40 @@ func (h *AlertNotificationPolicyHandler) GetSpecUID(resource grizzly.Resource) (
 	return AlertNotificationPolicyKind + "-UID", nil
 }
 
-// GetByUID retrieves JSON for a resource from an endpoint, by UID
+// GetByUID retrieves JSON for a resource from an endpoint, by UID. The
+// top-level routes pulled as AlertNotificationPolicyRoute resources are left out.
 func (h *AlertNotificationPolicyHandler) GetByUID(uid string) (*grizzly.Resource, error) {
-	return h.getRemoteAlertNotificationPolicy()
+	tree, err := h.getPolicyTree()
+	if err != nil {
+		return nil, err
+	}
+	if h.hasPolicyRoutes() {
+		tree.Routes, _, err = splitPolicyRoutes(tree, &models.Route{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.policyResource(tree)
 }
 
-// GetRemote retrieves a alertNotificationPolicy as a Resource
+// GetRemote retrieves a alertNotificationPolicy as a Resource, without the
+// top-level routes owned by others
 func (h *AlertNotificationPolicyHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
-	return h.getRemoteAlertNotificationPolicy()
+	tree, err := h.getPolicyTree()
+	if err != nil {
+		return nil, err
+	}
+	if h.hasPolicyRoutes() {
+		local, err := h.policy(resource)
+		if err != nil {
+			return nil, err
+		}
+		tree.Routes, _, err = splitPolicyRoutes(tree, local)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.policyResource(tree)
 }
 
 // ListRemote retrieves as list of UIDs of all remote resources
@@ -74,19 +103,8 @@ func (h *AlertNotificationPolicyHandler) Update(existing, resource grizzly.Resou
 	return h.putAlertNotificationPolicy(resource)
 }
 
-// getRemoteAlertNotificationPolicy retrieves a alertNotificationPolicy object from Grafana
-func (h *AlertNotificationPolicyHandler) getRemoteAlertNotificationPolicy() (*grizzly.Resource, error) {
-	client, err := h.Provider.(ClientProvider).Client()
-	if err != nil {
-		return nil, err
-	}
-	resp, err := client.Provisioning.GetPolicyTree()
-	if err != nil {
-		return nil, err
-	}
-
-	policy := resp.GetPayload()
-
+// policyResource turns a policy tree into a resource
+func (h *AlertNotificationPolicyHandler) policyResource(policy *models.Route) (*grizzly.Resource, error) {
 	// TODO: Turn spec into a real models.AlertNotificationPolicy object
 	spec, err := structToMap(policy)
 	if err != nil {
@@ -105,24 +123,97 @@ func (h *AlertNotificationPolicyHandler) getRemoteAlertNotificationPolicyList()
 }
 
 func (h *AlertNotificationPolicyHandler) putAlertNotificationPolicy(resource grizzly.Resource) error {
-	var alertNotificationPolicy models.Route
-	// TODO: Turn spec into a real models.AlertNotificationPolicy object
-	data, err := json.Marshal(resource.Spec())
+	alertNotificationPolicy, err := h.policy(resource)
 	if err != nil {
 		return err
 	}
 
-	err = json.Unmarshal(data, &alertNotificationPolicy)
-	if err != nil {
-		return err
+	// the routes owned by AlertNotificationPolicyRoute resources are kept
+	if h.hasPolicyRoutes() {
+		tree, err := h.getPolicyTree()
+		if err != nil {
+			return err
+		}
+		_, others, err := splitPolicyRoutes(tree, alertNotificationPolicy)
+		if err != nil {
+			return err
+		}
+		alertNotificationPolicy.Routes = append(alertNotificationPolicy.Routes, others...)
 	}
+
 	client, err := h.Provider.(ClientProvider).Client()
 	if err != nil {
 		return err
 	}
 	params := provisioning.NewPutPolicyTreeParams().
-		WithBody(&alertNotificationPolicy).
+		WithBody(alertNotificationPolicy).
 		WithXDisableProvenance(&stringtrue)
 	_, err = client.Provisioning.PutPolicyTree(params)
 	return err
 }
+
+// hasPolicyRoutes tells whether AlertNotificationPolicyRoute resources may own
+// top-level routes of the tree
+func (h *AlertNotificationPolicyHandler) hasPolicyRoutes() bool {
+	return h.Provider.(ClientProvider).Config().NotificationPolicyRoutes
+}
+
+func (h *AlertNotificationPolicyHandler) getPolicyTree() (*models.Route, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Provisioning.GetPolicyTree()
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetPayload(), nil
+}
+
+func (h *AlertNotificationPolicyHandler) policy(resource grizzly.Resource) (*models.Route, error) {
+	var alertNotificationPolicy models.Route
+	// TODO: Turn spec into a real models.AlertNotificationPolicy object
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &alertNotificationPolicy); err != nil {
+		return nil, err
+	}
+	return &alertNotificationPolicy, nil
+}
+
+// splitPolicyRoutes splits the top-level routes of a remote tree between the
+// ones owned by policy and the others: the routes with object_matchers
+// policy doesn't list.
+func splitPolicyRoutes(tree *models.Route, policy *models.Route) ([]*models.Route, []*models.Route, error) {
+	listed := map[string]bool{}
+	for _, route := range policy.Routes {
+		if route == nil || len(route.ObjectMatchers) == 0 {
+			continue
+		}
+		key, err := objectMatchersKey(route.ObjectMatchers)
+		if err != nil {
+			return nil, nil, err
+		}
+		listed[key] = true
+	}
+
+	var owned, others []*models.Route
+	for _, route := range tree.Routes {
+		if route == nil || len(route.ObjectMatchers) == 0 {
+			owned = append(owned, route)
+			continue
+		}
+		key, err := objectMatchersKey(route.ObjectMatchers)
+		if err != nil {
+			return nil, nil, err
+		}
+		if listed[key] {
+			owned = append(owned, route)
+		} else {
+			others = append(others, route)
+		}
+	}
+	return owned, others, nil
+}
@@ -0,0 +1,319 @@
+package grafana
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-openapi-client-go/client/provisioning"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const AlertNotificationPolicyRouteKind = "AlertNotificationPolicyRoute"
+
+const alertNotificationPolicyRoutePattern = "alert-notification-policy-routes/route-%s.%s"
+
+var _ grizzly.Handler = &AlertNotificationPolicyRouteHandler{}
+var _ grizzly.DeleteHandler = &AlertNotificationPolicyRouteHandler{}
+
+// AlertNotificationPolicyRouteHandler is a Grizzly Handler for subtrees of
+// the Grafana notification policy. Each resource owns the top-level route of
+// the policy tree with the same object_matchers: it is merged into the remote
+// tree on apply, leaving the other routes untouched.
+// Resources are named after their matchers, e.g. "team=platform", so that
+// pulled subtrees end up in the files they were applied from.
+// Subtrees are only managed when grafana.notification-policy-routes is set, as
+// the AlertNotificationPolicy owns the whole tree otherwise.
+type AlertNotificationPolicyRouteHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewAlertNotificationPolicyRouteHandler returns a new Grizzly Handler for notification policy subtrees
+func NewAlertNotificationPolicyRouteHandler(provider grizzly.Provider) *AlertNotificationPolicyRouteHandler {
+	return &AlertNotificationPolicyRouteHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, AlertNotificationPolicyRouteKind, false),
+	}
+}
+
+// policyRouteFilenameInvalidCharacters matches the characters of matchers
+// that are troublesome in filenames, e.g. "/", "|", "~" or "!"
+var policyRouteFilenameInvalidCharacters = regexp.MustCompile(`[^A-Za-z0-9=,._-]+`)
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *AlertNotificationPolicyRouteHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := policyRouteFilenameInvalidCharacters.ReplaceAllString(resource.Name(), "-")
+	return fmt.Sprintf(alertNotificationPolicyRoutePattern, filename, filetype)
+}
+
+// Unprepare removes unnecessary elements from a remote resource ready for presentation/comparison
+func (h *AlertNotificationPolicyRouteHandler) Unprepare(resource grizzly.Resource) *grizzly.Resource {
+	resource.DeleteSpecKey("provenance")
+	return &resource
+}
+
+// Validate checks that the resource has object_matchers, and is named after them
+func (h *AlertNotificationPolicyRouteHandler) Validate(resource grizzly.Resource) error {
+	key, err := h.GetSpecUID(resource)
+	if err != nil {
+		return err
+	}
+	if resource.Name() != key {
+		return fmt.Errorf("name of %s must be '%s', after its object_matchers", resource.Ref(), key)
+	}
+	return nil
+}
+
+// GetSpecUID returns the key of the object_matchers of a subtree
+func (h *AlertNotificationPolicyRouteHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	route, err := h.route(resource)
+	if err != nil {
+		return "", err
+	}
+	if len(route.ObjectMatchers) == 0 {
+		return "", fmt.Errorf("object_matchers of %s not specified", resource.Ref())
+	}
+	return objectMatchersKey(route.ObjectMatchers)
+}
+
+// GetByUID retrieves the subtree of the remote notification policy matching a key
+func (h *AlertNotificationPolicyRouteHandler) GetByUID(key string) (*grizzly.Resource, error) {
+	tree, err := h.getPolicyTree()
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := findPolicyRoute(tree, key)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		return nil, grizzly.ErrNotFound
+	}
+
+	spec, err := structToMap(tree.Routes[index])
+	if err != nil {
+		return nil, err
+	}
+	removeEmptyRouteFields(spec)
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), key, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// GetRemote retrieves the subtree of the remote notification policy owned by a resource
+func (h *AlertNotificationPolicyRouteHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	key, err := h.GetSpecUID(resource)
+	if err != nil {
+		return nil, err
+	}
+	return h.GetByUID(key)
+}
+
+// ListRemote retrieves the keys of the top-level routes of the remote
+// notification policy that have object_matchers, if subtrees are managed
+func (h *AlertNotificationPolicyRouteHandler) ListRemote() ([]string, error) {
+	if h.checkEnabled() != nil {
+		return []string{}, nil
+	}
+
+	tree, err := h.getPolicyTree()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, route := range tree.Routes {
+		if route == nil || len(route.ObjectMatchers) == 0 {
+			continue
+		}
+		key, err := objectMatchersKey(route.ObjectMatchers)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Add merges a subtree into the remote notification policy
+func (h *AlertNotificationPolicyRouteHandler) Add(resource grizzly.Resource) error {
+	return h.putPolicyRoute(resource)
+}
+
+// Update merges a subtree into the remote notification policy
+func (h *AlertNotificationPolicyRouteHandler) Update(existing, resource grizzly.Resource) error {
+	return h.putPolicyRoute(resource)
+}
+
+// Delete removes a subtree from the remote notification policy
+func (h *AlertNotificationPolicyRouteHandler) Delete(resource grizzly.Resource) error {
+	if err := h.checkEnabled(); err != nil {
+		return err
+	}
+
+	key, err := h.GetSpecUID(resource)
+	if err != nil {
+		return err
+	}
+
+	tree, err := h.getPolicyTree()
+	if err != nil {
+		return err
+	}
+
+	index, err := findPolicyRoute(tree, key)
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		return grizzly.ErrNotFound
+	}
+
+	tree.Routes = append(tree.Routes[:index], tree.Routes[index+1:]...)
+	return h.putPolicyTree(tree)
+}
+
+// putPolicyRoute replaces the top-level route with the same object_matchers
+// as resource in the remote tree, or appends it when there is none.
+func (h *AlertNotificationPolicyRouteHandler) putPolicyRoute(resource grizzly.Resource) error {
+	if err := h.checkEnabled(); err != nil {
+		return err
+	}
+
+	route, err := h.route(resource)
+	if err != nil {
+		return err
+	}
+
+	tree, err := h.getPolicyTree()
+	if err != nil {
+		return err
+	}
+
+	if err := mergePolicyRoute(tree, route); err != nil {
+		return err
+	}
+
+	return h.putPolicyTree(tree)
+}
+
+// mergePolicyRoute replaces the top-level route of tree with the same
+// object_matchers as route, or appends route when there is none.
+func mergePolicyRoute(tree *models.Route, route *models.Route) error {
+	key, err := objectMatchersKey(route.ObjectMatchers)
+	if err != nil {
+		return err
+	}
+
+	index, err := findPolicyRoute(tree, key)
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		tree.Routes = append(tree.Routes, route)
+	} else {
+		tree.Routes[index] = route
+	}
+	return nil
+}
+
+// checkEnabled reports an error unless subtrees are managed
+func (h *AlertNotificationPolicyRouteHandler) checkEnabled() error {
+	if !h.Provider.(ClientProvider).Config().NotificationPolicyRoutes {
+		return fmt.Errorf("%s resources are only managed when grafana.notification-policy-routes is set", h.Kind())
+	}
+	return nil
+}
+
+func (h *AlertNotificationPolicyRouteHandler) getPolicyTree() (*models.Route, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Provisioning.GetPolicyTree()
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetPayload(), nil
+}
+
+func (h *AlertNotificationPolicyRouteHandler) putPolicyTree(tree *models.Route) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	params := provisioning.NewPutPolicyTreeParams().
+		WithBody(tree).
+		WithXDisableProvenance(&stringtrue)
+	_, err = client.Provisioning.PutPolicyTree(params)
+	return err
+}
+
+func (h *AlertNotificationPolicyRouteHandler) route(resource grizzly.Resource) (*models.Route, error) {
+	var route models.Route
+	if err := unmarshalSpec(resource, &route); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+// findPolicyRoute returns the index of the top-level route of tree matching
+// key, or -1 if there is none.
+func findPolicyRoute(tree *models.Route, key string) (int, error) {
+	for i, route := range tree.Routes {
+		if route == nil || len(route.ObjectMatchers) == 0 {
+			continue
+		}
+		routeKey, err := objectMatchersKey(route.ObjectMatchers)
+		if err != nil {
+			return -1, err
+		}
+		if routeKey == key {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// objectMatchersKey identifies object matchers regardless of their order,
+// e.g. "severity=~critical|warning,team=platform".
+func objectMatchersKey(matchers models.ObjectMatchers) (string, error) {
+	parts := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		if len(matcher) != 3 {
+			return "", fmt.Errorf("invalid object matcher %v: expected [label, operator, value]", []string(matcher))
+		}
+		switch matcher[1] {
+		case "=", "!=", "=~", "!~":
+		default:
+			return "", fmt.Errorf("invalid object matcher %v: operator must be one of =, !=, =~, !~", []string(matcher))
+		}
+		parts = append(parts, strings.Join(matcher, ""))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ","), nil
+}
+
+// removeEmptyRouteFields removes the fields of a route, and of its nested
+// routes, that the API client returns as null when they aren't set.
+func removeEmptyRouteFields(route map[string]any) {
+	for key, value := range route {
+		if value == nil {
+			delete(route, key)
+		}
+	}
+	routes, _ := route["routes"].([]any)
+	for _, nested := range routes {
+		if nested, ok := nested.(map[string]any); ok {
+			removeEmptyRouteFields(nested)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package grafana
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertNotificationPolicyRouteHandler(t *testing.T) {
+	tree := map[string]any{
+		"receiver": "default",
+		"routes": []any{
+			map[string]any{"receiver": "database", "object_matchers": []any{[]any{"team", "=", "database"}}},
+		},
+	}
+
+	server := newFakeGrafana(t, map[string]http.HandlerFunc{
+		"GET /api/v1/provisioning/policies": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, tree)
+		},
+		"PUT /api/v1/provisioning/policies": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "true", r.Header.Get("X-Disable-Provenance"))
+			tree = nil
+			if !readJSON(t, r, &tree) {
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			writeJSON(w, map[string]any{})
+		},
+	})
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL, NotificationPolicyRoutes: true}, &config.HTTPConfig{})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewAlertNotificationPolicyRouteHandler(provider)
+
+	// wholeTreeProvider manages the notification policy as a whole
+	wholeTreeProvider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+	wholeTreeRegistry := grizzly.NewRegistry([]grizzly.Provider{wholeTreeProvider})
+
+	resource, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationPolicyRouteKind, "severity=critical,team=platform", map[string]any{
+		"receiver": "platform",
+		"object_matchers": []any{
+			[]any{"team", "=", "platform"},
+			[]any{"severity", "=", "critical"},
+		},
+	})
+	require.NoError(t, err)
+
+	pull := func(t *testing.T, registry grizzly.Registry) string {
+		t.Helper()
+		dir := t.TempDir()
+		recorder := grizzly.NewWriterRecorder(io.Discard, grizzly.EventToPlainText)
+		require.NoError(t, grizzly.Pull(registry, dir, grizzly.PullOptions{
+			OutputFormat: "yaml",
+			Targets:      []string{AlertNotificationPolicyKind, AlertNotificationPolicyRouteKind},
+		}, recorder))
+		return dir
+	}
+	pulledFile := func(t *testing.T, dir string, path string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	routeReceivers := func() []any {
+		receivers := []any{}
+		for _, route := range tree["routes"].([]any) {
+			receivers = append(receivers, route.(map[string]any)["receiver"])
+		}
+		return receivers
+	}
+
+	t.Run("subtrees are merged into the remote tree", func(t *testing.T) {
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceAdded])
+		require.Equal(t, "default", tree["receiver"])
+		require.Equal(t, []any{"database", "platform"}, routeReceivers())
+	})
+
+	t.Run("subtrees are compared without unset fields", func(t *testing.T) {
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceNotChanged])
+	})
+
+	t.Run("subtrees owned by others are left untouched", func(t *testing.T) {
+		resource.SetSpecString("receiver", "platform-oncall")
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, []any{"database", "platform-oncall"}, routeReceivers())
+	})
+
+	t.Run("subtrees are kept when the global policy is applied", func(t *testing.T) {
+		policy, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName, map[string]any{
+			"receiver": "fallback",
+			"routes": []any{
+				map[string]any{"receiver": "database-oncall", "object_matchers": []any{[]any{"team", "=", "database"}}},
+			},
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 1, applyResources(t, registry, policy).EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, "fallback", tree["receiver"])
+		require.Equal(t, []any{"database-oncall", "platform-oncall"}, routeReceivers())
+	})
+
+	t.Run("subtrees are extracted on pull", func(t *testing.T) {
+		keys, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"severity=critical,team=platform", "team=database"}, keys)
+
+		remote, err := handler.GetByUID("team=database")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"receiver":        "database-oncall",
+			"object_matchers": []any{[]any{"team", "=", "database"}},
+		}, remote.Spec())
+
+		_, err = handler.GetByUID("team=unknown")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("subtrees are pulled to their own files only", func(t *testing.T) {
+		dir := pull(t, registry)
+
+		require.NotContains(t, pulledFile(t, dir, alertNotificationPolicyFile), "oncall")
+		require.Contains(t, pulledFile(t, dir, "alert-notification-policy-routes/route-team=database.yaml"), "receiver: database-oncall")
+		require.Contains(t, pulledFile(t, dir, "alert-notification-policy-routes/route-severity=critical,team=platform.yaml"), "receiver: platform-oncall")
+	})
+
+	t.Run("subtrees are deleted", func(t *testing.T) {
+		require.NoError(t, handler.Delete(resource))
+		require.Equal(t, []any{"database-oncall"}, routeReceivers())
+	})
+
+	t.Run("subtrees are named after their matchers", func(t *testing.T) {
+		invalid, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationPolicyRouteKind, "platform", resource.Spec())
+		require.NoError(t, err)
+		require.ErrorContains(t, handler.Validate(invalid), "must be 'severity=critical,team=platform'")
+
+		invalid.SetSpecValue("object_matchers", []any{[]any{"team", "==", "platform"}})
+		require.ErrorContains(t, handler.Validate(invalid), "operator must be one of")
+	})
+
+	t.Run("subtrees are written to files named after their matchers", func(t *testing.T) {
+		route, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationPolicyRouteKind, "severity=~critical|warning,team!~db/.*", map[string]any{})
+		require.NoError(t, err)
+		require.Equal(t, "alert-notification-policy-routes/route-severity=-critical-warning,team-db-.-.yaml", handler.ResourceFilePath(route, "yaml"))
+	})
+
+	t.Run("the global policy owns the whole tree unless subtrees are managed", func(t *testing.T) {
+		resource, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationPolicyRouteKind, "team=platform", map[string]any{
+			"receiver":        "platform-oncall",
+			"object_matchers": []any{[]any{"team", "=", "platform"}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, handler.Add(resource))
+		require.Equal(t, []any{"database-oncall", "platform-oncall"}, routeReceivers())
+
+		dir := pull(t, wholeTreeRegistry)
+		policy := pulledFile(t, dir, alertNotificationPolicyFile)
+		require.Contains(t, policy, "receiver: database-oncall")
+		require.Contains(t, policy, "receiver: platform-oncall")
+		require.NoDirExists(t, filepath.Join(dir, "alert-notification-policy-routes"))
+
+		policyResource, err := grizzly.NewResource(provider.APIVersion(), AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName, map[string]any{
+			"receiver": "fallback",
+			"routes": []any{
+				map[string]any{"receiver": "database-oncall", "object_matchers": []any{[]any{"team", "=", "database"}}},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, applyResources(t, wholeTreeRegistry, policyResource).EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, []any{"database-oncall"}, routeReceivers())
+
+		wholeTreeHandler := NewAlertNotificationPolicyRouteHandler(wholeTreeProvider)
+		require.ErrorContains(t, wholeTreeHandler.Add(resource), "only managed when grafana.notification-policy-routes is set")
+	})
+}
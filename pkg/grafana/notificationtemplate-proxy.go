@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 
 	"github.com/go-chi/chi"
 	"github.com/grafana/grizzly/internal/httputils"
@@ -74,7 +75,7 @@ func (c *alertNotificationTemplateProxyConfigurator) StaticEndpoints() grizzly.S
 }
 
 // alertManagerConfigGet serves a partially mocked alert manager config to the UI.
-// Only the templates are served from Grizzly resources.
+// Only the templates and mute timings are served from Grizzly resources.
 func (c *alertNotificationTemplateProxyConfigurator) alertManagerConfigGet(s grizzly.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		templates := s.Resources.OfKind(KindAlertNotificationTemplate).AsList()
@@ -84,6 +85,8 @@ func (c *alertNotificationTemplateProxyConfigurator) alertManagerConfigGet(s gri
 			templatesMap[template.Name()] = template.GetSpecValue("template")
 		}
 
+		muteTimings := utils.Map(s.Resources.OfKind(KindAlertMuteTiming).AsList(), muteTimingAsConfig)
+
 		// The frontend expects most of these values :|
 		httputils.WriteJSON(w, map[string]any{
 			"template_files": templatesMap,
@@ -93,6 +96,7 @@ func (c *alertNotificationTemplateProxyConfigurator) alertManagerConfigGet(s gri
 					"group_by": []string{"grafana_folder", "alertname"},
 					"routes":   []map[string]any{},
 				},
+				"time_intervals": muteTimings,
 				"receivers": []map[string]any{
 					{
 						"name": "dummy-receiver",
@@ -116,11 +120,15 @@ func (c *alertNotificationTemplateProxyConfigurator) alertManagerConfigGet(s gri
 }
 
 // alertManagerConfigSave persists an alert manager config edited via the UI.
-// Only the templates are persisted as Grizzly resources.
+// Only the templates and mute timings are persisted as Grizzly resources.
 func (c *alertNotificationTemplateProxyConfigurator) alertManagerConfigSave(s grizzly.Server) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		input := &struct {
-			TemplateFiles map[string]string `json:"template_files"`
+			TemplateFiles      map[string]string `json:"template_files"`
+			AlertmanagerConfig struct {
+				TimeIntervals     []map[string]any `json:"time_intervals"`
+				MuteTimeIntervals []map[string]any `json:"mute_time_intervals"`
+			} `json:"alertmanager_config"`
 		}{}
 		content, err := io.ReadAll(r.Body)
 		if err != nil {
@@ -146,6 +154,28 @@ func (c *alertNotificationTemplateProxyConfigurator) alertManagerConfigSave(s gr
 			}
 		}
 
+		muteTimings := append(input.AlertmanagerConfig.TimeIntervals, input.AlertmanagerConfig.MuteTimeIntervals...)
+		for _, config := range muteTimings {
+			name, _ := config["name"].(string)
+			muteTiming, found := s.Resources.Find(grizzly.NewResourceRef(KindAlertMuteTiming, name))
+			if !found {
+				httputils.Error(w, fmt.Sprintf("Mute timing %s not found", name), fmt.Errorf("mute timing %s not found", name), http.StatusNotFound)
+				return
+			}
+
+			// the whole config is sent back: mute timings are only written when they changed
+			if reflect.DeepEqual(asJSONValue(muteTimingAsConfig(muteTiming)["time_intervals"]), asJSONValue(config["time_intervals"])) {
+				continue
+			}
+
+			muteTiming.SetSpecValue("time_intervals", config["time_intervals"])
+
+			if err := s.UpdateResource(muteTiming); err != nil {
+				httputils.Error(w, err.Error(), err, http.StatusInternalServerError)
+				return
+			}
+		}
+
 		httputils.WriteJSON(w, map[string]string{
 			"message": "configuration created",
 		})
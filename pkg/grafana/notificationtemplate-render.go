@@ -0,0 +1,364 @@
+package grafana
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// NotificationSamples lists the built-in sample notifications
+// notification templates can be rendered with.
+var NotificationSamples = []string{"firing", "resolved", "mixed"}
+
+//go:embed notification-samples/*.json
+var notificationSamplesFS embed.FS
+
+// KV is a set of labels or annotations, as seen by notification templates.
+type KV map[string]string
+
+// Pair is a label or annotation.
+type Pair struct {
+	Name, Value string
+}
+
+// Pairs is a list of labels or annotations.
+type Pairs []Pair
+
+// Names returns the names of the pairs.
+func (ps Pairs) Names() []string {
+	names := make([]string, 0, len(ps))
+	for _, pair := range ps {
+		names = append(names, pair.Name)
+	}
+	return names
+}
+
+// Values returns the values of the pairs.
+func (ps Pairs) Values() []string {
+	values := make([]string, 0, len(ps))
+	for _, pair := range ps {
+		values = append(values, pair.Value)
+	}
+	return values
+}
+
+// SortedPairs returns the pairs of the set, sorted by name.
+func (kv KV) SortedPairs() Pairs {
+	pairs := make(Pairs, 0, len(kv))
+	for _, name := range kv.Names() {
+		pairs = append(pairs, Pair{Name: name, Value: kv[name]})
+	}
+	return pairs
+}
+
+// Remove returns a copy of the set without the given names.
+func (kv KV) Remove(names []string) KV {
+	removed := make(KV, len(kv))
+	for name, value := range kv {
+		removed[name] = value
+	}
+	for _, name := range names {
+		delete(removed, name)
+	}
+	return removed
+}
+
+// Names returns the sorted names of the set.
+func (kv KV) Names() []string {
+	names := make([]string, 0, len(kv))
+	for name := range kv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Values returns the values of the set, sorted by name.
+func (kv KV) Values() []string {
+	return kv.SortedPairs().Values()
+}
+
+// NotificationAlert is an alert, as seen by notification templates.
+type NotificationAlert struct {
+	Status       string             `json:"status"`
+	Labels       KV                 `json:"labels"`
+	Annotations  KV                 `json:"annotations"`
+	StartsAt     time.Time          `json:"startsAt"`
+	EndsAt       time.Time          `json:"endsAt"`
+	GeneratorURL string             `json:"generatorURL"`
+	Fingerprint  string             `json:"fingerprint"`
+	SilenceURL   string             `json:"silenceURL"`
+	DashboardURL string             `json:"dashboardURL"`
+	PanelURL     string             `json:"panelURL"`
+	Values       map[string]float64 `json:"values"`
+	ValueString  string             `json:"valueString"`
+	OrgID        int64              `json:"orgId"`
+}
+
+// NotificationAlerts is a list of alerts.
+type NotificationAlerts []NotificationAlert
+
+// Firing returns the firing alerts of the list.
+func (as NotificationAlerts) Firing() NotificationAlerts {
+	return as.withStatus("firing")
+}
+
+// Resolved returns the resolved alerts of the list.
+func (as NotificationAlerts) Resolved() NotificationAlerts {
+	return as.withStatus("resolved")
+}
+
+func (as NotificationAlerts) withStatus(status string) NotificationAlerts {
+	alerts := NotificationAlerts{}
+	for _, alert := range as {
+		if alert.Status == status {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// NotificationData is the data notification templates are executed with,
+// as found in the payload of webhook notifications.
+type NotificationData struct {
+	Receiver          string             `json:"receiver"`
+	Status            string             `json:"status"`
+	Alerts            NotificationAlerts `json:"alerts"`
+	GroupLabels       KV                 `json:"groupLabels"`
+	CommonLabels      KV                 `json:"commonLabels"`
+	CommonAnnotations KV                 `json:"commonAnnotations"`
+	ExternalURL       string             `json:"externalURL"`
+}
+
+// LoadNotificationSample returns a built-in sample notification.
+func LoadNotificationSample(name string) (NotificationData, error) {
+	content, err := notificationSamplesFS.ReadFile(path.Join("notification-samples", name+".json"))
+	if err != nil {
+		return NotificationData{}, fmt.Errorf("unknown sample notification '%s', expected one of %s", name, strings.Join(NotificationSamples, ", "))
+	}
+	return ParseNotificationData(content)
+}
+
+// ParseNotificationData parses a notification from its JSON payload.
+func ParseNotificationData(content []byte) (NotificationData, error) {
+	var data NotificationData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return NotificationData{}, fmt.Errorf("parsing notification data: %w", err)
+	}
+	return data, nil
+}
+
+// RenderedTemplate is the output of a notification template, or the error
+// executing it.
+type RenderedTemplate struct {
+	Name   string
+	Output string
+	Err    error
+}
+
+var templateDefinitionRegexp = regexp.MustCompile(`{{-?\s*define\s+"([^"]+)"`)
+
+// RenderNotificationTemplates executes notification templates locally, with
+// the Alertmanager functions and the default Grafana templates. The named
+// templates are rendered, or every template defined by the resources if none
+// is given. Errors report the template and line they occurred at.
+func RenderNotificationTemplates(resources []grizzly.Resource, names []string, data NotificationData) ([]RenderedTemplate, error) {
+	root, err := texttemplate.New("").Funcs(notificationTemplateFuncs()).Parse(defaultNotificationTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := []string{}
+	for _, resource := range resources {
+		content, _ := resource.GetSpecValue("template").(string)
+		if _, err := root.New(resource.Name()).Parse(content); err != nil {
+			return nil, err
+		}
+
+		definitions := templateDefinitionRegexp.FindAllStringSubmatch(content, -1)
+		if len(definitions) == 0 {
+			defined = append(defined, resource.Name())
+		}
+		for _, definition := range definitions {
+			defined = append(defined, definition[1])
+		}
+	}
+
+	if len(names) == 0 {
+		names = defined
+	}
+
+	rendered := make([]RenderedTemplate, 0, len(names))
+	for _, name := range names {
+		tmpl := root.Lookup(name)
+		if tmpl == nil {
+			return nil, fmt.Errorf("template '%s' is not defined", name)
+		}
+
+		var output bytes.Buffer
+		err := tmpl.Execute(&output, data)
+		rendered = append(rendered, RenderedTemplate{Name: name, Output: output.String(), Err: err})
+	}
+	return rendered, nil
+}
+
+// notificationTemplateFuncs returns the functions Alertmanager makes available to notification templates.
+func notificationTemplateFuncs() texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"toUpper":   strings.ToUpper,
+		"toLower":   strings.ToLower,
+		"title":     cases.Title(language.AmericanEnglish).String,
+		"trimSpace": strings.TrimSpace,
+		"join": func(sep string, s []string) string {
+			return strings.Join(s, sep)
+		},
+		"match": regexp.MatchString,
+		"safeHtml": func(text string) template.HTML {
+			return template.HTML(text) //nolint:gosec
+		},
+		"safeUrl": func(text string) template.URL {
+			return template.URL(text) //nolint:gosec
+		},
+		"urlUnescape": url.QueryUnescape,
+		"reReplaceAll": func(pattern, repl, text string) string {
+			re := regexp.MustCompile(pattern)
+			return re.ReplaceAllString(text, repl)
+		},
+		"stringSlice": func(s ...string) []string {
+			return s
+		},
+		"date": func(format string, t time.Time) string {
+			return t.Format(format)
+		},
+		"tz": func(name string, t time.Time) (time.Time, error) {
+			location, err := time.LoadLocation(name)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return t.In(location), nil
+		},
+		"since":            time.Since,
+		"humanizeDuration": humanizeDuration,
+		"toJson": func(v any) (string, error) {
+			content, err := json.Marshal(v)
+			return string(content), err
+		},
+	}
+}
+
+// humanizeDuration formats a number of seconds as Grafana does, e.g. "1m 30s"
+// for 90. Seconds are given as a number, a string, or a duration.
+func humanizeDuration(value any) (string, error) {
+	v, err := templateSeconds(value)
+	if err != nil {
+		return "", err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprintf("%.4g", v), nil
+	}
+	if v == 0 {
+		return fmt.Sprintf("%.4gs", v), nil
+	}
+
+	if math.Abs(v) >= 1 {
+		sign := ""
+		if v < 0 {
+			sign = "-"
+			v = -v
+		}
+		duration := int64(v)
+		seconds := duration % 60
+		minutes := (duration / 60) % 60
+		hours := (duration / 60 / 60) % 24
+		days := duration / 60 / 60 / 24
+		switch {
+		case days != 0:
+			return fmt.Sprintf("%s%dd %dh %dm %ds", sign, days, hours, minutes, seconds), nil
+		case hours != 0:
+			return fmt.Sprintf("%s%dh %dm %ds", sign, hours, minutes, seconds), nil
+		case minutes != 0:
+			return fmt.Sprintf("%s%dm %ds", sign, minutes, seconds), nil
+		}
+		return fmt.Sprintf("%s%.4gs", sign, v), nil
+	}
+
+	// fractions of a second are shown with a prefix, e.g. "500ms"
+	prefix := ""
+	for _, p := range []string{"m", "u", "n", "p", "f", "a", "z", "y"} {
+		if math.Abs(v) >= 1 {
+			break
+		}
+		prefix = p
+		v *= 1000
+	}
+	return fmt.Sprintf("%.4g%ss", v, prefix), nil
+}
+
+// templateSeconds converts a value given to a template function to seconds.
+func templateSeconds(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case time.Duration:
+		return v.Seconds(), nil
+	default:
+		return 0, fmt.Errorf("can't convert %T to seconds", value)
+	}
+}
+
+// defaultNotificationTemplates are the templates Grafana provides to every
+// notification template.
+const defaultNotificationTemplates = `
+{{ define "__subject" }}[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ if gt (.Alerts.Resolved | len) 0 }}, RESOLVED:{{ .Alerts.Resolved | len }}{{ end }}{{ end }}] {{ .GroupLabels.SortedPairs.Values | join " " }} {{ if gt (len .CommonLabels) (len .GroupLabels) }}({{ with .CommonLabels.Remove .GroupLabels.Names }}{{ .Values | join " " }}{{ end }}){{ end }}{{ end }}
+
+{{ define "__text_values_list" }}{{ if len .Values }}{{ $first := true }}{{ range $refID, $value := .Values -}}
+{{ if $first }}{{ $first = false }}{{ else }}, {{ end }}{{ $refID }}={{ $value }}{{ end -}}
+{{ else }}[no value]{{ end }}{{ end }}
+
+{{ define "__text_alert_list" }}{{ range . }}
+Value: {{ template "__text_values_list" . }}
+Labels:
+{{ range .Labels.SortedPairs }} - {{ .Name }} = {{ .Value }}
+{{ end }}Annotations:
+{{ range .Annotations.SortedPairs }} - {{ .Name }} = {{ .Value }}
+{{ end }}{{ if gt (len .GeneratorURL) 0 }}Source: {{ .GeneratorURL }}
+{{ end }}{{ if gt (len .SilenceURL) 0 }}Silence: {{ .SilenceURL }}
+{{ end }}{{ if gt (len .DashboardURL) 0 }}Dashboard: {{ .DashboardURL }}
+{{ end }}{{ if gt (len .PanelURL) 0 }}Panel: {{ .PanelURL }}
+{{ end }}{{ end }}{{ end }}
+
+{{ define "default.title" }}{{ template "__subject" . }}{{ end }}
+
+{{ define "default.message" }}{{ if gt (len .Alerts.Firing) 0 }}**Firing**
+{{ template "__text_alert_list" .Alerts.Firing }}{{ if gt (len .Alerts.Resolved) 0 }}
+
+{{ end }}{{ end }}{{ if gt (len .Alerts.Resolved) 0 }}**Resolved**
+{{ template "__text_alert_list" .Alerts.Resolved }}{{ end }}{{ end }}
+`
@@ -0,0 +1,69 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderNotificationTemplates(t *testing.T) {
+	newTemplate := func(t *testing.T, template string) grizzly.Resource {
+		t.Helper()
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", KindAlertNotificationTemplate, "custom", map[string]any{
+			"template": template,
+		})
+		require.NoError(t, err)
+		return resource
+	}
+
+	t.Run("templates are rendered with the built-in samples", func(t *testing.T) {
+		for _, sample := range NotificationSamples {
+			data, err := LoadNotificationSample(sample)
+			require.NoError(t, err)
+			require.NotEmpty(t, data.Alerts)
+		}
+
+		data, err := LoadNotificationSample("mixed")
+		require.NoError(t, err)
+
+		resource := newTemplate(t, `{{ define "custom.title" }}{{ template "default.title" . }}{{ end }}
+{{ define "custom.message" }}{{ range .Alerts.Firing }}{{ .Labels.service | title }} {{ .StartsAt | date "15:04" }}{{ end }}{{ end }}`)
+		rendered, err := RenderNotificationTemplates([]grizzly.Resource{resource}, nil, data)
+		require.NoError(t, err)
+		require.Equal(t, []RenderedTemplate{
+			{Name: "custom.title", Output: "[FIRING:1, RESOLVED:1] HighLatency Platform (critical)"},
+			{Name: "custom.message", Output: "Payments 10:02"},
+		}, rendered)
+	})
+
+	t.Run("templates are selected by name", func(t *testing.T) {
+		resource := newTemplate(t, `{{ define "custom.title" }}{{ .Status }}{{ end }}{{ define "custom.message" }}{{ .Receiver }}{{ end }}`)
+		rendered, err := RenderNotificationTemplates([]grizzly.Resource{resource}, []string{"custom.message"}, NotificationData{Receiver: "email"})
+		require.NoError(t, err)
+		require.Equal(t, []RenderedTemplate{{Name: "custom.message", Output: "email"}}, rendered)
+
+		_, err = RenderNotificationTemplates([]grizzly.Resource{resource}, []string{"unknown"}, NotificationData{})
+		require.ErrorContains(t, err, "template 'unknown' is not defined")
+	})
+
+	t.Run("durations are humanized from seconds", func(t *testing.T) {
+		resource := newTemplate(t, `{{ define "durations" }}{{ humanizeDuration 90 }}|{{ humanizeDuration 93784.5 }}|{{ humanizeDuration "1.5" }}|{{ humanizeDuration 0.25 }}|{{ humanizeDuration 0 }}{{ end }}`)
+		rendered, err := RenderNotificationTemplates([]grizzly.Resource{resource}, nil, NotificationData{})
+		require.NoError(t, err)
+		require.Equal(t, []RenderedTemplate{{Name: "durations", Output: "1m 30s|1d 2h 3m 4s|1.5s|250ms|0s"}}, rendered)
+
+		rendered, err = RenderNotificationTemplates([]grizzly.Resource{newTemplate(t, `{{ define "invalid" }}{{ humanizeDuration "soon" }}{{ end }}`)}, nil, NotificationData{})
+		require.NoError(t, err)
+		require.ErrorContains(t, rendered[0].Err, "invalid syntax")
+	})
+
+	t.Run("errors report line numbers", func(t *testing.T) {
+		_, err := RenderNotificationTemplates([]grizzly.Resource{newTemplate(t, "{{ define \"broken\" }}\n{{ if }}{{ end }}")}, nil, NotificationData{})
+		require.ErrorContains(t, err, "template: custom:2:")
+
+		rendered, err := RenderNotificationTemplates([]grizzly.Resource{newTemplate(t, "{{ define \"broken\" }}\n\n{{ .Unknown }}{{ end }}")}, nil, NotificationData{})
+		require.NoError(t, err)
+		require.ErrorContains(t, rendered[0].Err, "template: custom:3:")
+	})
+}
@@ -0,0 +1,241 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"gopkg.in/yaml.v3"
+)
+
+const grafanaOperatorAPIVersion = "grafana.integreatly.org/v1beta1"
+
+// OperatorKinds lists the kinds ExportOperatorManifests exports
+var OperatorKinds = []string{DashboardKind, DashboardFolderKind, DatasourceKind, AlertRuleGroupKind, AlertContactPointKind}
+
+// OperatorOptions describes the custom resources of grafana-operator
+// resources are exported to.
+type OperatorOptions struct {
+	// Namespace of the custom resources, left out when empty
+	Namespace string
+	// InstanceSelector selects the labels of the Grafana instances the
+	// custom resources apply to
+	InstanceSelector map[string]string
+}
+
+// operatorManifest describes how a kind of resource is exported
+type operatorManifest struct {
+	kind string
+	dir  string
+	spec func(resource grizzly.Resource) (map[string]any, []string, error)
+}
+
+var operatorManifests = map[string]operatorManifest{
+	DashboardKind:         {kind: "GrafanaDashboard", dir: "dashboards", spec: operatorDashboardSpec},
+	DashboardFolderKind:   {kind: "GrafanaFolder", dir: "folders", spec: operatorFolderSpec},
+	DatasourceKind:        {kind: "GrafanaDatasource", dir: "datasources", spec: operatorDatasourceSpec},
+	AlertRuleGroupKind:    {kind: "GrafanaAlertRuleGroup", dir: "alert-rule-groups", spec: operatorAlertRuleGroupSpec},
+	AlertContactPointKind: {kind: "GrafanaContactPoint", dir: "contact-points", spec: operatorContactPointSpec},
+}
+
+// ExportOperatorManifests translates dashboards, folders, datasources, alert
+// rule groups and contact points into custom resources of grafana-operator.
+// It returns a manifest per resource, by path, and warnings about what
+// couldn't be exported. Resources of other kinds are ignored.
+func ExportOperatorManifests(resources grizzly.Resources, opts OperatorOptions) (map[string][]byte, []string, error) {
+	files := map[string][]byte{}
+	sources := map[string]grizzly.ResourceRef{}
+	var warnings []string
+
+	instanceSelector := map[string]any{}
+	for key, value := range opts.InstanceSelector {
+		instanceSelector[key] = value
+	}
+
+	for _, kind := range OperatorKinds {
+		manifest := operatorManifests[kind]
+		for _, resource := range sortedByName(resources.OfKind(kind).AsList()) {
+			if org := resource.GetMetadata(orgMetadata); org != "" {
+				warnings = append(warnings, fmt.Sprintf("%s: organization %s can't be exported, the resource applies to the organization of the selected instances", resource.Ref(), org))
+			}
+
+			spec, specWarnings, err := manifest.spec(resource)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", resource.Ref(), err)
+			}
+			for _, warning := range specWarnings {
+				warnings = append(warnings, fmt.Sprintf("%s: %s", resource.Ref(), warning))
+			}
+			spec["instanceSelector"] = map[string]any{"matchLabels": instanceSelector}
+
+			name := grizzly.KubernetesName(resource.Name())
+			filename := path.Join(manifest.dir, name+".yaml")
+			if source, found := sources[filename]; found {
+				return nil, nil, fmt.Errorf("%s and %s are both exported as %s %s", source, resource.Ref(), manifest.kind, name)
+			}
+			sources[filename] = resource.Ref()
+
+			content, err := yaml.Marshal(grizzly.KubernetesManifest{
+				APIVersion: grafanaOperatorAPIVersion,
+				Kind:       manifest.kind,
+				Metadata:   grizzly.KubernetesMetadata{Name: name, Namespace: opts.Namespace},
+				Spec:       spec,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			files[filename] = content
+		}
+	}
+	return files, warnings, nil
+}
+
+func operatorDashboardSpec(resource grizzly.Resource) (map[string]any, []string, error) {
+	dashboard, err := json.MarshalIndent(resource.Spec(), "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spec := map[string]any{"json": string(dashboard)}
+	if folderUID := resource.GetMetadata("folder"); folderUID != "" && folderUID != generalFolderUID {
+		spec["folderUID"] = folderUID
+	}
+	return spec, nil, nil
+}
+
+func operatorFolderSpec(resource grizzly.Resource) (map[string]any, []string, error) {
+	title, _ := resource.GetSpecString("title")
+	spec := map[string]any{
+		"uid":   resource.Name(),
+		"title": title,
+	}
+	if parentUID, ok := resource.GetSpecString("parentUid"); ok && parentUID != "" {
+		spec["parentFolderUID"] = parentUID
+	}
+	return spec, nil, nil
+}
+
+func operatorDatasourceSpec(resource grizzly.Resource) (map[string]any, []string, error) {
+	datasource := withoutEmptyValues(resource.Spec())
+	for _, key := range []string{"id", "version", "orgId", "readOnly"} {
+		delete(datasource, key)
+	}
+
+	datasourceSpec, valuesFrom, warnings := operatorValuesFrom(datasource, "", operatorSecretName(resource))
+	spec := map[string]any{"datasource": datasourceSpec}
+	if len(valuesFrom) != 0 {
+		spec["valuesFrom"] = valuesFrom
+	}
+	return spec, warnings, nil
+}
+
+func operatorAlertRuleGroupSpec(resource grizzly.Resource) (map[string]any, []string, error) {
+	rules := exportedAlertRules(resource.Spec())
+	for _, rule := range rules {
+		// the only field named differently from the API
+		rule := rule.(map[string]any)
+		if settings, ok := rule["notification_settings"]; ok {
+			rule["notificationSettings"] = settings
+			delete(rule, "notification_settings")
+		}
+	}
+
+	spec := map[string]any{
+		"name":      resource.GetSpecValue("title"),
+		"folderUID": resource.GetSpecValue("folderUid"),
+		"rules":     rules,
+	}
+	if interval := resource.GetSpecValue("interval"); interval != nil {
+		spec["interval"] = fmt.Sprintf("%vs", interval)
+	}
+	return spec, nil, nil
+}
+
+func operatorContactPointSpec(resource grizzly.Resource) (map[string]any, []string, error) {
+	spec := withoutEmptyValues(resource.Spec())
+	delete(spec, "uid")
+
+	settings, _ := spec["settings"].(map[string]any)
+	settings, valuesFrom, warnings := operatorValuesFrom(settings, "", operatorSecretName(resource))
+	spec["settings"] = settings
+	if len(valuesFrom) != 0 {
+		spec["valuesFrom"] = valuesFrom
+	}
+	return spec, warnings, nil
+}
+
+// operatorSecretName is the name of the Kubernetes secret holding the
+// secrets of a resource
+func operatorSecretName(resource grizzly.Resource) string {
+	return grizzly.KubernetesName(resource.Name()) + "-credentials"
+}
+
+// operatorValuesFrom replaces the references to environment variables of
+// value by placeholders grafana-operator fills with the key of the same
+// name of a secret. It returns the valuesFrom entries filling them. Other
+// secret references can't be exported, they're left out.
+func operatorValuesFrom(value map[string]any, prefix string, secretName string) (map[string]any, []any, []string) {
+	result := make(map[string]any, len(value))
+	var valuesFrom []any
+	var warnings []string
+
+	for _, key := range sortedKeys(value) {
+		targetPath := strings.TrimPrefix(prefix+"."+key, ".")
+		item, ok := value[key].(map[string]any)
+		if !ok {
+			if containsSecretReference(value[key]) {
+				warnings = append(warnings, fmt.Sprintf("secrets of %s can't be exported, left out", targetPath))
+				continue
+			}
+			result[key] = value[key]
+			continue
+		}
+
+		reference, isReference := item[grizzly.SecretKey].(map[string]any)
+		if !isReference || len(item) != 1 {
+			nested, nestedValuesFrom, nestedWarnings := operatorValuesFrom(item, targetPath, secretName)
+			result[key] = nested
+			valuesFrom = append(valuesFrom, nestedValuesFrom...)
+			warnings = append(warnings, nestedWarnings...)
+			continue
+		}
+
+		env, ok := reference["env"].(string)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("secret %s isn't read from an environment variable, left out", targetPath))
+			continue
+		}
+		result[key] = "${" + env + "}"
+		valuesFrom = append(valuesFrom, map[string]any{
+			"targetPath": targetPath,
+			"valueFrom": map[string]any{
+				"secretKeyRef": map[string]any{"name": secretName, "key": env},
+			},
+		})
+	}
+
+	return result, valuesFrom, warnings
+}
+
+func containsSecretReference(value any) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		if _, ok := v[grizzly.SecretKey]; ok && len(v) == 1 {
+			return true
+		}
+		for _, item := range v {
+			if containsSecretReference(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if containsSecretReference(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportOperatorManifests(t *testing.T) {
+	resource := func(kind, name string, spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", kind, name, spec)
+		require.NoError(t, err)
+		return resource
+	}
+
+	dashboard := resource(DashboardKind, "Service_Overview", map[string]any{"uid": "Service_Overview", "title": "Service overview"})
+	dashboard.SetMetadata("folder", "platform")
+	datasource := resource(DatasourceKind, "prom", map[string]any{
+		"id":   12,
+		"uid":  "prom",
+		"name": "Prometheus",
+		"type": "prometheus",
+		"secureJsonData": map[string]any{
+			"basicAuthPassword": map[string]any{grizzly.SecretKey: map[string]any{"env": "PROM_PASSWORD"}},
+			"tlsClientKey":      map[string]any{grizzly.SecretKey: map[string]any{"file": "prom.key"}},
+		},
+	})
+	datasource.SetMetadata(orgMetadata, "2")
+
+	resources := grizzly.NewResources(
+		dashboard,
+		datasource,
+		resource(DashboardFolderKind, "platform", map[string]any{"uid": "platform", "title": "Platform", "parentUid": "teams"}),
+		resource(AlertRuleGroupKind, "platform.availability", map[string]any{
+			"folderUid": "platform",
+			"title":     "availability",
+			"interval":  60,
+			"rules": []any{map[string]any{
+				"uid":                   "target-down",
+				"title":                 "TargetDown",
+				"folderUID":             "platform",
+				"ruleGroup":             "availability",
+				"notification_settings": map[string]any{"receiver": "platform"},
+			}},
+		}),
+		resource(AlertContactPointKind, "platform-slack", map[string]any{
+			"uid":      "platform-slack",
+			"name":     "platform",
+			"type":     "slack",
+			"settings": map[string]any{"url": map[string]any{grizzly.SecretKey: map[string]any{"env": "SLACK_URL"}}},
+		}),
+		resource(KindAlertNotificationTemplate, "slack", map[string]any{"name": "slack"}),
+	)
+
+	files, warnings, err := ExportOperatorManifests(resources, OperatorOptions{
+		Namespace:        "monitoring",
+		InstanceSelector: map[string]string{"dashboards": "grafana"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Datasource.prom: organization 2 can't be exported, the resource applies to the organization of the selected instances",
+		"Datasource.prom: secret secureJsonData.tlsClientKey isn't read from an environment variable, left out",
+	}, warnings)
+	require.Len(t, files, 5)
+
+	manifest := func(path string) map[string]any {
+		var manifest map[string]any
+		require.Contains(t, files, path)
+		require.NoError(t, yaml.Unmarshal(files[path], &manifest))
+		require.Equal(t, "grafana.integreatly.org/v1beta1", manifest["apiVersion"])
+		require.Equal(t, "monitoring", manifest["metadata"].(map[string]any)["namespace"])
+		spec := manifest["spec"].(map[string]any)
+		require.Equal(t, map[string]any{"matchLabels": map[string]any{"dashboards": "grafana"}}, spec["instanceSelector"])
+		delete(spec, "instanceSelector")
+		return manifest
+	}
+
+	dashboardManifest := manifest("dashboards/service-overview.yaml")
+	require.Equal(t, "GrafanaDashboard", dashboardManifest["kind"])
+	require.Equal(t, map[string]any{"name": "service-overview", "namespace": "monitoring"}, dashboardManifest["metadata"])
+	require.Equal(t, map[string]any{
+		"folderUID": "platform",
+		"json":      "{\n  \"title\": \"Service overview\",\n  \"uid\": \"Service_Overview\"\n}",
+	}, dashboardManifest["spec"])
+
+	require.Equal(t, map[string]any{"uid": "platform", "title": "Platform", "parentFolderUID": "teams"}, manifest("folders/platform.yaml")["spec"])
+
+	require.Equal(t, map[string]any{
+		"datasource": map[string]any{
+			"uid":            "prom",
+			"name":           "Prometheus",
+			"type":           "prometheus",
+			"secureJsonData": map[string]any{"basicAuthPassword": "${PROM_PASSWORD}"},
+		},
+		"valuesFrom": []any{map[string]any{
+			"targetPath": "secureJsonData.basicAuthPassword",
+			"valueFrom":  map[string]any{"secretKeyRef": map[string]any{"name": "prom-credentials", "key": "PROM_PASSWORD"}},
+		}},
+	}, manifest("datasources/prom.yaml")["spec"])
+
+	require.Equal(t, map[string]any{
+		"name":      "availability",
+		"folderUID": "platform",
+		"interval":  "60s",
+		"rules": []any{map[string]any{
+			"uid":                  "target-down",
+			"title":                "TargetDown",
+			"notificationSettings": map[string]any{"receiver": "platform"},
+		}},
+	}, manifest("alert-rule-groups/platform.availability.yaml")["spec"])
+
+	require.Equal(t, map[string]any{
+		"name":     "platform",
+		"type":     "slack",
+		"settings": map[string]any{"url": "${SLACK_URL}"},
+		"valuesFrom": []any{map[string]any{
+			"targetPath": "url",
+			"valueFrom":  map[string]any{"secretKeyRef": map[string]any{"name": "platform-slack-credentials", "key": "SLACK_URL"}},
+		}},
+	}, manifest("contact-points/platform-slack.yaml")["spec"])
+
+	t.Run("resources exported under the same name are rejected", func(t *testing.T) {
+		resources.Add(resource(DashboardKind, "service-overview", map[string]any{"uid": "service-overview"}))
+		_, _, err := ExportOperatorManifests(resources, OperatorOptions{})
+		require.ErrorContains(t, err, "are both exported as GrafanaDashboard service-overview")
+	})
+}
@@ -0,0 +1,256 @@
+package grafana
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/go-openapi/runtime"
+	gclient "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/client/orgs"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const KindGrafanaOrganization = "GrafanaOrganization"
+
+const organizationPattern = "organizations/organization-%s.%s"
+
+var _ grizzly.Handler = &OrganizationHandler{}
+var _ grizzly.DeleteHandler = &OrganizationHandler{}
+
+// OrganizationHandler is a Grizzly Handler for Grafana organizations.
+// Organizations are identified by their name. Renaming one requires its ID in
+// spec.id, as the old name can't be known otherwise.
+type OrganizationHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewOrganizationHandler returns a new Grizzly Handler for Grafana organizations
+func NewOrganizationHandler(provider grizzly.Provider) *OrganizationHandler {
+	return &OrganizationHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, KindGrafanaOrganization, false),
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *OrganizationHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	return fmt.Sprintf(organizationPattern, resource.Name(), filetype)
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *OrganizationHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("name") {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that spec.name, if set, matches the name of the resource
+func (h *OrganizationHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("spec.name '%s' and metadata.name '%s', don't match", name, resource.Name())
+	}
+	if _, err := organizationID(resource); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *OrganizationHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, ok := resource.GetSpecString("name")
+	if !ok {
+		return "", fmt.Errorf("name not specified")
+	}
+	return name, nil
+}
+
+// GetByUID retrieves an organization by name
+func (h *OrganizationHandler) GetByUID(name string) (*grizzly.Resource, error) {
+	client, err := h.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Orgs.GetOrgByName(name)
+	if err != nil {
+		return nil, organizationError(err)
+	}
+
+	return h.organizationResource(response.GetPayload(), false)
+}
+
+// GetRemote retrieves an organization as a Resource. Organizations with an ID
+// in their spec are retrieved by ID, to detect renames.
+func (h *OrganizationHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	id, err := organizationID(resource)
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return h.GetByUID(resource.Name())
+	}
+
+	client, err := h.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.Orgs.GetOrgByID(id)
+	if err != nil {
+		return nil, organizationError(err)
+	}
+
+	return h.organizationResource(response.GetPayload(), true)
+}
+
+// ListRemote retrieves a list of sorted names of all remote organizations
+func (h *OrganizationHandler) ListRemote() ([]string, error) {
+	client, err := h.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		page    int64 = 1
+		perPage int64 = 1000
+		names   []string
+	)
+	for {
+		params := orgs.NewSearchOrgsParams().WithPage(&page).WithPerpage(&perPage)
+		response, err := client.Orgs.SearchOrgs(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, org := range response.GetPayload() {
+			names = append(names, org.Name)
+		}
+		if int64(len(response.GetPayload())) < perPage {
+			break
+		}
+		page++
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Add creates an organization via the API
+func (h *OrganizationHandler) Add(resource grizzly.Resource) error {
+	client, err := h.adminClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Orgs.CreateOrg(&models.CreateOrgCommand{Name: resource.Name()})
+	return err
+}
+
+// Update renames an organization via the API
+func (h *OrganizationHandler) Update(existing, resource grizzly.Resource) error {
+	client, err := h.adminClient()
+	if err != nil {
+		return err
+	}
+
+	id, err := h.remoteID(client, existing)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Orgs.UpdateOrg(id, &models.UpdateOrgForm{Name: resource.Name()})
+	return err
+}
+
+// Delete deletes an organization, and everything it holds, via the API
+func (h *OrganizationHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.adminClient()
+	if err != nil {
+		return err
+	}
+
+	id, err := h.remoteID(client, resource)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Orgs.DeleteOrgByID(id)
+	return organizationError(err)
+}
+
+// adminClient returns a client for the organizations API, which is reserved to
+// Grafana server admins authenticated with basic auth. Requests target no
+// organization in particular.
+func (h *OrganizationHandler) adminClient() (*gclient.GrafanaHTTPAPI, error) {
+	provider := h.Provider.(*Provider)
+
+	httpClient, err := provider.httpClient(nil)
+	if err != nil {
+		return nil, err
+	}
+	if provider.config.User == "" {
+		return nil, fmt.Errorf("managing %s resources requires the basic-auth credentials of a Grafana server admin: set grafana.user", KindGrafanaOrganization)
+	}
+
+	return provider.newClient(httpClient)
+}
+
+// remoteID returns the ID of an organization, from its spec or by looking up
+// its name.
+func (h *OrganizationHandler) remoteID(client *gclient.GrafanaHTTPAPI, resource grizzly.Resource) (int64, error) {
+	id, err := organizationID(resource)
+	if err != nil || id != 0 {
+		return id, err
+	}
+
+	response, err := client.Orgs.GetOrgByName(resource.Name())
+	if err != nil {
+		return 0, organizationError(err)
+	}
+	return response.GetPayload().ID, nil
+}
+
+func (h *OrganizationHandler) organizationResource(org *models.OrgDetailsDTO, withID bool) (*grizzly.Resource, error) {
+	spec := map[string]any{
+		"name": org.Name,
+	}
+	if withID {
+		spec["id"] = org.ID
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), org.Name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// organizationID returns the ID set in the spec of an organization, or 0.
+func organizationID(resource grizzly.Resource) (int64, error) {
+	switch id := resource.GetSpecValue("id").(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(id), nil
+	case int64:
+		return id, nil
+	case float64:
+		if id == float64(int64(id)) {
+			return int64(id), nil
+		}
+	}
+	return 0, fmt.Errorf("spec.id of %s must be an integer", resource.Ref())
+}
+
+// organizationError translates the "not found" responses of the
+// organizations API, which its OpenAPI definition doesn't describe.
+func organizationError(err error) error {
+	var gErr *runtime.APIError
+	if errors.As(err, &gErr) && gErr.IsCode(http.StatusNotFound) {
+		return grizzly.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package grafana
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrganizationsAPI serves the organizations API from memory, to server
+// admins only.
+func fakeOrganizationsAPI(t *testing.T, orgs map[int64]string) *httptest.Server {
+	t.Helper()
+
+	admin := func(handler func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if user, _, ok := r.BasicAuth(); !ok || user != "admin" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Empty(t, r.Header.Get("X-Grafana-Org-Id"))
+			handler(w, r)
+		}
+	}
+	writeOrg := func(w http.ResponseWriter, id int64) {
+		name, ok := orgs[id]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, map[string]any{"id": id, "name": name})
+	}
+	// orgID returns the organization id of the request
+	orgID := func(w http.ResponseWriter, r *http.Request) (int64, bool) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if !assert.NoError(t, err) {
+			writeNotFound(w)
+			return 0, false
+		}
+		return id, true
+	}
+
+	return newFakeGrafana(t, map[string]http.HandlerFunc{
+		"GET /api/orgs": admin(func(w http.ResponseWriter, r *http.Request) {
+			var list []map[string]any
+			for id, name := range orgs {
+				list = append(list, map[string]any{"id": id, "name": name})
+			}
+			writeJSON(w, list)
+		}),
+		"POST /api/orgs": admin(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			if !readJSON(t, r, &body) {
+				return
+			}
+			id := int64(len(orgs) + 1)
+			orgs[id] = body["name"]
+			writeJSON(w, map[string]any{"orgId": id})
+		}),
+		"GET /api/orgs/name/{name}": admin(func(w http.ResponseWriter, r *http.Request) {
+			for id, name := range orgs {
+				if name == r.PathValue("name") {
+					writeOrg(w, id)
+					return
+				}
+			}
+			writeNotFound(w)
+		}),
+		"GET /api/orgs/{id}": admin(func(w http.ResponseWriter, r *http.Request) {
+			if id, ok := orgID(w, r); ok {
+				writeOrg(w, id)
+			}
+		}),
+		"PUT /api/orgs/{id}": admin(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			if id, ok := orgID(w, r); ok && readJSON(t, r, &body) {
+				orgs[id] = body["name"]
+				writeJSON(w, map[string]any{})
+			}
+		}),
+		"DELETE /api/orgs/{id}": admin(func(w http.ResponseWriter, r *http.Request) {
+			if id, ok := orgID(w, r); ok {
+				delete(orgs, id)
+				writeJSON(w, map[string]any{})
+			}
+		}),
+	})
+}
+
+func TestOrganizationHandler(t *testing.T) {
+	orgs := map[int64]string{1: "Main Org.", 2: "customer-a"}
+	server := fakeOrganizationsAPI(t, orgs)
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL, User: "admin", Token: "admin", OrgID: 2}, &config.HTTPConfig{})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewOrganizationHandler(provider)
+	recorder := grizzly.NewWriterRecorder(io.Discard, grizzly.EventToPlainText)
+
+	organization := func(name string, spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource(provider.APIVersion(), KindGrafanaOrganization, name, spec)
+		require.NoError(t, err)
+		return resource
+	}
+
+	t.Run("organizations are handled first", func(t *testing.T) {
+		require.Equal(t, KindGrafanaOrganization, registry.HandlerOrder[0].Kind())
+	})
+
+	t.Run("organizations are listed", func(t *testing.T) {
+		names, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"Main Org.", "customer-a"}, names)
+	})
+
+	t.Run("organizations are created and renamed", func(t *testing.T) {
+		err := grizzly.Apply(registry, grizzly.NewResources(organization("customer-b", map[string]any{})), false, recorder)
+		require.NoError(t, err)
+		require.Equal(t, "customer-b", orgs[3])
+
+		err = grizzly.Apply(registry, grizzly.NewResources(organization("customer-c", map[string]any{"id": 3})), false, recorder)
+		require.NoError(t, err)
+		require.Equal(t, "customer-c", orgs[3])
+	})
+
+	t.Run("organizations are deleted", func(t *testing.T) {
+		err := grizzly.Delete(registry, grizzly.NewResources(organization("customer-c", map[string]any{})), false, recorder)
+		require.NoError(t, err)
+		require.NotContains(t, orgs, int64(3))
+
+		_, err = handler.GetByUID("customer-c")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("basic-auth credentials are required", func(t *testing.T) {
+		tokenProvider := NewProvider(&config.GrafanaConfig{URL: server.URL, Token: "token"}, &config.HTTPConfig{})
+		_, err := NewOrganizationHandler(tokenProvider).ListRemote()
+		require.ErrorContains(t, err, "requires the basic-auth credentials of a Grafana server admin")
+	})
+}
@@ -0,0 +1,517 @@
+package grafana
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	gclient "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/client/dashboard_permissions"
+	"github.com/grafana/grafana-openapi-client-go/client/folder_permissions"
+	"github.com/grafana/grafana-openapi-client-go/client/service_accounts"
+	"github.com/grafana/grafana-openapi-client-go/client/users"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const (
+	KindFolderPermissions    = "FolderPermissions"
+	KindDashboardPermissions = "DashboardPermissions"
+)
+
+const (
+	folderPermissionsPattern    = "folder-permissions/folder-%s.%s"
+	dashboardPermissionsPattern = "dashboard-permissions/dashboard-%s.%s"
+)
+
+// permissionLevels maps the permission levels of resources to their value in
+// the permissions API.
+var permissionLevels = map[string]models.PermissionType{
+	"View":  1,
+	"Edit":  2,
+	"Admin": 4,
+}
+
+// permissionPrincipals are the keys identifying who a permission is granted
+// to, in the order permissions are sorted by.
+var permissionPrincipals = []string{"role", "team", "user", "serviceAccount"}
+
+var _ grizzly.Handler = &PermissionsHandler{}
+
+// defaultPermissions are the permissions Grafana grants on folders and
+// dashboards it creates, besides the ones they inherit.
+var defaultPermissions = []permissionEntry{
+	{principal: "role", name: "Editor", level: "Edit"},
+	{principal: "role", name: "Viewer", level: "View"},
+}
+
+// PermissionsHandler is a Grizzly Handler for the permissions of Grafana
+// folders or dashboards. Resources apply to the folder or dashboard referenced
+// by spec.folder or spec.dashboard, or named after its UID otherwise, and list
+// who is granted which permission: basic roles, teams (by name), users (by
+// login or email) and service accounts (by name).
+type PermissionsHandler struct {
+	grizzly.BaseHandler
+	pattern string
+	// reference is the key of the spec referencing the folder or dashboard
+	reference string
+	list      func(client *gclient.GrafanaHTTPAPI, uid string) ([]*models.DashboardACLInfoDTO, error)
+	update    func(client *gclient.GrafanaHTTPAPI, uid string, command *models.UpdateDashboardACLCommand) error
+	uids      func() ([]string, error)
+}
+
+// NewFolderPermissionsHandler returns a new Grizzly Handler for the
+// permissions of Grafana folders
+func NewFolderPermissionsHandler(provider grizzly.Provider) *PermissionsHandler {
+	return &PermissionsHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, KindFolderPermissions, false),
+		pattern:     folderPermissionsPattern,
+		reference:   "folder",
+		list: func(client *gclient.GrafanaHTTPAPI, uid string) ([]*models.DashboardACLInfoDTO, error) {
+			response, err := client.FolderPermissions.GetFolderPermissionList(uid)
+			if err != nil {
+				var gErr *folder_permissions.GetFolderPermissionListNotFound
+				if errors.As(err, &gErr) {
+					return nil, grizzly.ErrNotFound
+				}
+				return nil, err
+			}
+			return response.GetPayload(), nil
+		},
+		update: func(client *gclient.GrafanaHTTPAPI, uid string, command *models.UpdateDashboardACLCommand) error {
+			_, err := client.FolderPermissions.UpdateFolderPermissions(uid, command)
+			return err
+		},
+		uids: func() ([]string, error) {
+			folders, err := NewFolderHandler(provider).ListRemote()
+			if err != nil {
+				return nil, err
+			}
+			uids := make([]string, 0, len(folders))
+			for _, uid := range folders {
+				// the General folder has no permissions of its own
+				if !strings.EqualFold(uid, DefaultFolder) {
+					uids = append(uids, uid)
+				}
+			}
+			return uids, nil
+		},
+	}
+}
+
+// NewDashboardPermissionsHandler returns a new Grizzly Handler for the
+// permissions of Grafana dashboards
+func NewDashboardPermissionsHandler(provider grizzly.Provider) *PermissionsHandler {
+	return &PermissionsHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, KindDashboardPermissions, false),
+		pattern:     dashboardPermissionsPattern,
+		reference:   "dashboard",
+		list: func(client *gclient.GrafanaHTTPAPI, uid string) ([]*models.DashboardACLInfoDTO, error) {
+			response, err := client.DashboardPermissions.GetDashboardPermissionsListByUID(uid)
+			if err != nil {
+				var gErr *dashboard_permissions.GetDashboardPermissionsListByUIDNotFound
+				if errors.As(err, &gErr) {
+					return nil, grizzly.ErrNotFound
+				}
+				return nil, err
+			}
+			return response.GetPayload(), nil
+		},
+		update: func(client *gclient.GrafanaHTTPAPI, uid string, command *models.UpdateDashboardACLCommand) error {
+			_, err := client.DashboardPermissions.UpdateDashboardPermissionsByUID(uid, command)
+			return err
+		},
+		uids: NewDashboardHandler(provider).ListRemote,
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *PermissionsHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	return fmt.Sprintf(h.pattern, resource.Name(), filetype)
+}
+
+// Validate checks that each permission names one principal and a known level
+func (h *PermissionsHandler) Validate(resource grizzly.Resource) error {
+	if _, err := h.GetSpecUID(resource); err != nil {
+		return err
+	}
+	_, err := permissionEntries(resource)
+	return err
+}
+
+// GetSpecUID returns the UID of the folder or dashboard referenced by the
+// spec, or the name of the resource when there is no reference
+func (h *PermissionsHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	value := resource.GetSpecValue(h.reference)
+	if value == nil {
+		return resource.Name(), nil
+	}
+	uid, ok := value.(string)
+	if !ok || uid == "" {
+		return "", fmt.Errorf("spec.%s of %s must be the UID of a %s", h.reference, resource.Ref(), h.reference)
+	}
+	return uid, nil
+}
+
+// GetByUID retrieves the permissions of a folder or dashboard
+func (h *PermissionsHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	items, err := h.getACL(uid)
+	if err != nil {
+		return nil, err
+	}
+	return h.permissionsResource(uid, items, nil)
+}
+
+// GetRemote retrieves permissions as a Resource. Remote permissions are listed
+// in the order of the resource, and name users the way it does.
+func (h *PermissionsHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	uid, err := h.GetSpecUID(resource)
+	if err != nil {
+		return nil, err
+	}
+	local, err := permissionEntries(resource)
+	if err != nil {
+		return nil, err
+	}
+	items, err := h.getACL(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := h.permissionsResource(resource.Name(), items, local)
+	if err != nil {
+		return nil, err
+	}
+	if value := resource.GetSpecValue(h.reference); value != nil {
+		remote.SetSpecValue(h.reference, value)
+	}
+	return remote, nil
+}
+
+// ListRemote retrieves the UIDs of the folders or dashboards whose
+// permissions aren't the ones Grafana grants by default
+func (h *PermissionsHandler) ListRemote() ([]string, error) {
+	uids, err := h.uids()
+	if err != nil {
+		return nil, err
+	}
+
+	listed := []string{}
+	for _, uid := range uids {
+		items, err := h.getACL(uid)
+		if errors.Is(err, grizzly.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !hasDefaultPermissions(items) {
+			listed = append(listed, uid)
+		}
+	}
+	return listed, nil
+}
+
+// Add sets permissions via the API
+func (h *PermissionsHandler) Add(resource grizzly.Resource) error {
+	return h.putPermissions(resource)
+}
+
+// Update sets permissions via the API
+func (h *PermissionsHandler) Update(existing, resource grizzly.Resource) error {
+	return h.putPermissions(resource)
+}
+
+func (h *PermissionsHandler) getACL(uid string) ([]*models.DashboardACLInfoDTO, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+	return h.list(client, uid)
+}
+
+// permissionsResource returns the permissions of items as a resource named
+// name, listed in the order of local
+func (h *PermissionsHandler) permissionsResource(name string, items []*models.DashboardACLInfoDTO, local []permissionEntry) (*grizzly.Resource, error) {
+	var (
+		client          *gclient.GrafanaHTTPAPI
+		err             error
+		serviceAccounts map[int64]string
+	)
+	remote := make([]permissionEntry, 0, len(items))
+	for _, item := range items {
+		if item.Inherited {
+			continue
+		}
+
+		entry := permissionEntry{level: permissionLevelName(item.Permission)}
+		switch {
+		case item.Role != "":
+			entry.principal, entry.name = "role", item.Role
+		case item.TeamID != 0:
+			entry.principal, entry.name = "team", item.Team
+		case item.UserID != 0:
+			if serviceAccounts == nil {
+				if client, err = h.Provider.(ClientProvider).Client(); err != nil {
+					return nil, err
+				}
+				if serviceAccounts, err = listServiceAccounts(client); err != nil {
+					return nil, err
+				}
+			}
+			if account, ok := serviceAccounts[item.UserID]; ok {
+				entry.principal, entry.name = "serviceAccount", account
+			} else {
+				entry.principal, entry.name, entry.email = "user", item.UserLogin, item.UserEmail
+			}
+		default:
+			continue
+		}
+		remote = append(remote, entry)
+	}
+
+	spec := map[string]any{
+		"permissions": alignPermissions(local, remote),
+	}
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (h *PermissionsHandler) putPermissions(resource grizzly.Resource) error {
+	uid, err := h.GetSpecUID(resource)
+	if err != nil {
+		return err
+	}
+
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	entries, err := permissionEntries(resource)
+	if err != nil {
+		return err
+	}
+
+	command := &models.UpdateDashboardACLCommand{
+		Items: make([]*models.DashboardACLUpdateItem, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		item := &models.DashboardACLUpdateItem{Permission: permissionLevels[entry.level]}
+		switch entry.principal {
+		case "role":
+			item.Role = entry.name
+		case "team":
+			team, err := getTeamByName(client, entry.name)
+			if err != nil {
+				return fmt.Errorf("team %s: %w", entry.name, err)
+			}
+			item.TeamID = team.ID
+		case "user":
+			user, err := client.Users.GetUserByLoginOrEmail(entry.name)
+			if err != nil {
+				var gErr *users.GetUserByLoginOrEmailNotFound
+				if errors.As(err, &gErr) {
+					return fmt.Errorf("user %s: %w", entry.name, grizzly.ErrNotFound)
+				}
+				return err
+			}
+			item.UserID = user.GetPayload().ID
+		case "serviceAccount":
+			serviceAccount, err := getServiceAccountByName(client, entry.name)
+			if err != nil {
+				return fmt.Errorf("service account %s: %w", entry.name, err)
+			}
+			item.UserID = serviceAccount.ID
+		}
+		command.Items = append(command.Items, item)
+	}
+
+	return h.update(client, uid, command)
+}
+
+// hasDefaultPermissions tells whether the permissions of a folder or
+// dashboard, besides inherited ones, are among the ones Grafana grants by
+// default
+func hasDefaultPermissions(items []*models.DashboardACLInfoDTO) bool {
+	for _, item := range items {
+		if item.Inherited {
+			continue
+		}
+		if item.TeamID != 0 || item.UserID != 0 {
+			return false
+		}
+		entry := permissionEntry{principal: "role", name: item.Role, level: permissionLevelName(item.Permission)}
+		if !slices.Contains(defaultPermissions, entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// permissionEntry is a permission granted to a principal.
+type permissionEntry struct {
+	principal string
+	name      string
+	// email is the email of remote users, which resources may name users by.
+	email string
+	level string
+}
+
+func (e permissionEntry) matches(local permissionEntry) bool {
+	if e.principal != local.principal {
+		return false
+	}
+	if strings.EqualFold(e.name, local.name) {
+		return true
+	}
+	return e.principal == "user" && e.email != "" && strings.EqualFold(e.email, local.name)
+}
+
+func (e permissionEntry) toMap() map[string]any {
+	return map[string]any{
+		e.principal:  e.name,
+		"permission": e.level,
+	}
+}
+
+// permissionEntries returns the permissions listed in spec.permissions.
+func permissionEntries(resource grizzly.Resource) ([]permissionEntry, error) {
+	raw := resource.GetSpecValue("permissions")
+	if raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("spec.permissions of %s must be a list", resource.Ref())
+	}
+
+	entries := make([]permissionEntry, 0, len(items))
+	for i, item := range items {
+		permission, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("spec.permissions[%d] of %s must be a map", i, resource.Ref())
+		}
+
+		var entry permissionEntry
+		for _, principal := range permissionPrincipals {
+			name, ok := permission[principal].(string)
+			if !ok {
+				continue
+			}
+			if entry.principal != "" {
+				return nil, fmt.Errorf("spec.permissions[%d] of %s must grant a permission to one of %s", i, resource.Ref(), strings.Join(permissionPrincipals, ", "))
+			}
+			entry.principal, entry.name = principal, name
+		}
+		if entry.principal == "" || len(permission) != 2 {
+			return nil, fmt.Errorf("spec.permissions[%d] of %s must grant a permission to one of %s", i, resource.Ref(), strings.Join(permissionPrincipals, ", "))
+		}
+
+		entry.level, _ = permission["permission"].(string)
+		if _, ok := permissionLevels[entry.level]; !ok {
+			return nil, fmt.Errorf("spec.permissions[%d] of %s: permission must be one of View, Edit or Admin", i, resource.Ref())
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// alignPermissions lists remote permissions as local lists them: permissions
+// matching a local one come first, named as written locally, followed by the
+// others sorted by principal and name.
+func alignPermissions(local []permissionEntry, remote []permissionEntry) []any {
+	matched := make([]bool, len(remote))
+	permissions := make([]any, 0, len(remote))
+
+	for _, localEntry := range local {
+		for i, remoteEntry := range remote {
+			if matched[i] || !remoteEntry.matches(localEntry) {
+				continue
+			}
+			matched[i] = true
+			remoteEntry.name = localEntry.name
+			permissions = append(permissions, remoteEntry.toMap())
+			break
+		}
+	}
+
+	var others []permissionEntry
+	for i, remoteEntry := range remote {
+		if !matched[i] {
+			others = append(others, remoteEntry)
+		}
+	}
+	principalOrder := make(map[string]int, len(permissionPrincipals))
+	for i, principal := range permissionPrincipals {
+		principalOrder[principal] = i
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		if others[i].principal != others[j].principal {
+			return principalOrder[others[i].principal] < principalOrder[others[j].principal]
+		}
+		return others[i].name < others[j].name
+	})
+	for _, entry := range others {
+		permissions = append(permissions, entry.toMap())
+	}
+
+	return permissions
+}
+
+func permissionLevelName(permission models.PermissionType) string {
+	for name, level := range permissionLevels {
+		if level == permission {
+			return name
+		}
+	}
+	return fmt.Sprint(permission)
+}
+
+// listServiceAccounts returns the names of the service accounts of the
+// organization, by ID.
+func listServiceAccounts(client *gclient.GrafanaHTTPAPI) (map[int64]string, error) {
+	var (
+		page    int64 = 1
+		perPage int64 = 1000
+		names         = map[int64]string{}
+	)
+	for {
+		params := service_accounts.NewSearchOrgServiceAccountsWithPagingParams().WithPage(&page).WithPerpage(&perPage)
+		response, err := client.ServiceAccounts.SearchOrgServiceAccountsWithPaging(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, serviceAccount := range response.GetPayload().ServiceAccounts {
+			names[serviceAccount.ID] = serviceAccount.Name
+		}
+		if int64(len(response.GetPayload().ServiceAccounts)) < perPage {
+			return names, nil
+		}
+		page++
+	}
+}
+
+func getServiceAccountByName(client *gclient.GrafanaHTTPAPI, name string) (*models.ServiceAccountDTO, error) {
+	params := service_accounts.NewSearchOrgServiceAccountsWithPagingParams().WithQuery(&name)
+	response, err := client.ServiceAccounts.SearchOrgServiceAccountsWithPaging(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, serviceAccount := range response.GetPayload().ServiceAccounts {
+		if serviceAccount.Name == name {
+			return serviceAccount, nil
+		}
+	}
+	return nil, grizzly.ErrNotFound
+}
@@ -0,0 +1,156 @@
+package grafana
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionsHandler(t *testing.T) {
+	// permissions of the "reports" folder, as sent by the API
+	var acl []map[string]any
+
+	server := newFakeGrafana(t, map[string]http.HandlerFunc{
+		"GET /api/folders/reports/permissions": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, acl)
+		},
+		"POST /api/folders/reports/permissions": func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Items []map[string]any `json:"items"`
+			}
+			if !readJSON(t, r, &body) {
+				return
+			}
+			acl = nil
+			for _, item := range body.Items {
+				switch {
+				case item["teamId"] != nil:
+					item["team"] = "platform"
+				case item["userId"] == 1.0:
+					item["userLogin"], item["userEmail"] = "alice", "alice@example.com"
+				case item["userId"] == 2.0:
+					item["userLogin"] = "sa-1-ci"
+				}
+				acl = append(acl, item)
+			}
+			writeJSON(w, map[string]any{})
+		},
+		"GET /api/folders/unknown/permissions": func(w http.ResponseWriter, r *http.Request) {
+			writeNotFound(w)
+		},
+		"GET /api/teams/search": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]any{"teams": []any{map[string]any{"id": 5, "name": "platform"}}})
+		},
+		"GET /api/users/lookup": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "alice@example.com", r.URL.Query().Get("loginOrEmail"))
+			writeJSON(w, map[string]any{"id": 1, "login": "alice", "email": "alice@example.com"})
+		},
+		"GET /api/serviceaccounts/search": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]any{"serviceAccounts": []any{map[string]any{"id": 2, "name": "ci"}}})
+		},
+		"GET /api/search": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, []any{
+				map[string]any{"uid": "custom", "type": "dash-db"},
+				map[string]any{"uid": "defaults", "type": "dash-db"},
+				map[string]any{"uid": "inherited", "type": "dash-db"},
+			})
+		},
+		"GET /api/dashboards/uid/{uid}/permissions": func(w http.ResponseWriter, r *http.Request) {
+			editor := map[string]any{"role": "Editor", "permission": 2}
+			viewer := map[string]any{"role": "Viewer", "permission": 1}
+			inherited := map[string]any{"role": "Editor", "permission": 2, "inherited": true}
+			switch r.PathValue("uid") {
+			case "custom":
+				writeJSON(w, []any{viewer, map[string]any{"teamId": 5, "team": "platform", "permission": 4}})
+			case "defaults":
+				writeJSON(w, []any{editor, viewer})
+			default:
+				writeJSON(w, []any{inherited})
+			}
+		},
+	})
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewFolderPermissionsHandler(provider)
+
+	permissions := []any{
+		map[string]any{"team": "platform", "permission": "Admin"},
+		map[string]any{"user": "alice@example.com", "permission": "Edit"},
+		map[string]any{"serviceAccount": "ci", "permission": "Edit"},
+		map[string]any{"role": "Viewer", "permission": "View"},
+	}
+	resource, err := grizzly.NewResource(provider.APIVersion(), KindFolderPermissions, "reports", map[string]any{
+		"permissions": permissions,
+	})
+	require.NoError(t, err)
+
+	t.Run("permissions are applied", func(t *testing.T) {
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, []map[string]any{
+			{"teamId": 5.0, "team": "platform", "permission": 4.0},
+			{"userId": 1.0, "userLogin": "alice", "userEmail": "alice@example.com", "permission": 2.0},
+			{"userId": 2.0, "userLogin": "sa-1-ci", "permission": 2.0},
+			{"role": "Viewer", "permission": 1.0},
+		}, acl)
+	})
+
+	t.Run("permissions are compared as written", func(t *testing.T) {
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceNotChanged])
+	})
+
+	t.Run("permissions are pulled", func(t *testing.T) {
+		acl = append(acl, map[string]any{"role": "Editor", "permission": 1, "inherited": true})
+
+		remote, err := handler.GetByUID("reports")
+		require.NoError(t, err)
+		require.Equal(t, []any{
+			map[string]any{"role": "Viewer", "permission": "View"},
+			map[string]any{"team": "platform", "permission": "Admin"},
+			map[string]any{"user": "alice", "permission": "Edit"},
+			map[string]any{"serviceAccount": "ci", "permission": "Edit"},
+		}, remote.GetSpecValue("permissions"))
+
+		_, err = handler.GetByUID("unknown")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("permissions can reference their folder", func(t *testing.T) {
+		referencing, err := grizzly.NewResource(provider.APIVersion(), KindFolderPermissions, "reports-access", map[string]any{
+			"folder":      "reports",
+			"permissions": permissions,
+		})
+		require.NoError(t, err)
+
+		uid, err := handler.GetSpecUID(referencing)
+		require.NoError(t, err)
+		require.Equal(t, "reports", uid)
+		require.Equal(t, 1, applyResources(t, registry, referencing).EventCounts[grizzly.ResourceNotChanged])
+	})
+
+	t.Run("only non-default permissions are listed", func(t *testing.T) {
+		uids, err := NewDashboardPermissionsHandler(provider).ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"custom"}, uids)
+	})
+
+	t.Run("invalid permissions are reported", func(t *testing.T) {
+		invalid, err := grizzly.NewResource(provider.APIVersion(), KindFolderPermissions, "reports", map[string]any{
+			"permissions": []any{
+				map[string]any{"team": "platform", "user": "alice", "permission": "Admin"},
+			},
+		})
+		require.NoError(t, err)
+		require.ErrorContains(t, handler.Validate(invalid), "must grant a permission to one of role, team, user, serviceAccount")
+
+		invalid.SetSpecValue("permissions", []any{map[string]any{"team": "platform", "permission": "Owner"}})
+		require.ErrorContains(t, handler.Validate(invalid), "permission must be one of View, Edit or Admin")
+
+		invalid.SetSpecValue("folder", 42)
+		require.ErrorContains(t, handler.Validate(invalid), "spec.folder of FolderPermissions.reports must be the UID of a folder")
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"path/filepath"
+	"strconv"
 
 	gclient "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grizzly/internal/httputils"
@@ -14,21 +16,38 @@ import (
 	"github.com/grafana/grizzly/pkg/grizzly"
 )
 
+// orgMetadata is the metadata selecting the organization of a resource, by ID
+// or name.
+const orgMetadata = "org"
+
 // Provider is a grizzly.Provider implementation for Grafana.
 type Provider struct {
-	config *config.GrafanaConfig
-	client *gclient.GrafanaHTTPAPI
+	config     *config.GrafanaConfig
+	httpConfig *config.HTTPConfig
+	limiter    *httputils.RateLimiter
+	client     *gclient.GrafanaHTTPAPI
+	// orgID is the ID of the organization configured by name, once looked up.
+	orgID int64
+	// orgProviders are the providers of the organizations selected by
+	// resources, by metadata value.
+	orgProviders map[string]*Provider
+	// folderTitlePaths caches the title paths of the folders of the
+	// organization.
+	folderTitlePaths folderTitlePaths
 }
 
 type ClientProvider interface {
 	Client() (*gclient.GrafanaHTTPAPI, error)
+	HTTPClient() (*http.Client, error)
 	Config() *config.GrafanaConfig
 }
 
 // NewProvider instantiates a new Provider.
-func NewProvider(config *config.GrafanaConfig) *Provider {
+func NewProvider(config *config.GrafanaConfig, httpConfig *config.HTTPConfig) *Provider {
 	return &Provider{
-		config: config,
+		config:     config,
+		httpConfig: httpConfig,
+		limiter:    httputils.NewRateLimiter(httpConfig),
 	}
 }
 
@@ -84,6 +103,20 @@ func (p *Provider) Client() (*gclient.GrafanaHTTPAPI, error) {
 		return p.client, nil
 	}
 
+	httpClient, err := p.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	grafanaClient, err := p.newClient(httpClient)
+	if err != nil {
+		return nil, err
+	}
+	p.client = grafanaClient
+	return grafanaClient, nil
+}
+
+func (p *Provider) newClient(httpClient *http.Client) (*gclient.GrafanaHTTPAPI, error) {
 	parsedURL, err := url.Parse(p.config.URL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid Grafana URL")
@@ -93,30 +126,124 @@ func (p *Provider) Client() (*gclient.GrafanaHTTPAPI, error) {
 		WithHost(parsedURL.Host).
 		WithSchemes([]string{parsedURL.Scheme}).
 		WithBasePath(filepath.Join(parsedURL.Path, "api"))
+	transportConfig.Client = httpClient
 
-	httpClient, err := httputils.NewHTTPClient()
+	if p.config.Token != "" {
+		if p.config.User != "" {
+			transportConfig.BasicAuth = url.UserPassword(p.config.User, p.config.Token)
+		} else {
+			transportConfig.APIKey = p.config.Token
+		}
+	}
+
+	return gclient.NewHTTPClientWithConfig(nil, transportConfig), nil
+}
+
+// HTTPClient returns a client to send requests to Grafana, sharing the rate
+// limit of the provider. It resolves the credentials of the provider if needed,
+// and targets the configured organization.
+func (p *Provider) HTTPClient() (*http.Client, error) {
+	orgID, err := p.OrgID()
 	if err != nil {
 		return nil, err
 	}
-	transportConfig.Client = httpClient
 
-	if parsedURL.Scheme == "https" && p.config.InsecureSkipVerify {
-		transportConfig.TLSConfig = &tls.Config{
+	var headers map[string]string
+	if orgID != 0 {
+		headers = map[string]string{gclient.OrgIDHeader: strconv.FormatInt(orgID, 10)}
+	}
+
+	return p.httpClient(headers)
+}
+
+func (p *Provider) httpClient(headers map[string]string) (*http.Client, error) {
+	if err := p.config.ResolveCredentials(); err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if p.config.InsecureSkipVerify {
+		tlsConfig = &tls.Config{
 			InsecureSkipVerify: true,
 			ServerName:         p.config.TLSHost,
 		}
 	}
 
-	if p.config.Token != "" {
-		if p.config.User != "" {
-			transportConfig.BasicAuth = url.UserPassword(p.config.User, p.config.Token)
-		} else {
-			transportConfig.APIKey = p.config.Token
-		}
+	return httputils.NewHTTPClient(httputils.ClientOptions{
+		Config:    p.httpConfig,
+		Limiter:   p.limiter,
+		TLSConfig: tlsConfig,
+		Headers:   headers,
+	})
+}
+
+// OrgID returns the ID of the organization requests are sent to, or 0 for the
+// default organization of the credentials. Organizations configured by name
+// are looked up once.
+func (p *Provider) OrgID() (int64, error) {
+	if p.config.OrgID != 0 || p.config.OrgName == "" {
+		return p.config.OrgID, nil
 	}
-	grafanaClient := gclient.NewHTTPClientWithConfig(nil, transportConfig)
-	p.client = grafanaClient
-	return grafanaClient, nil
+	if p.orgID != 0 {
+		return p.orgID, nil
+	}
+
+	httpClient, err := p.httpClient(nil)
+	if err != nil {
+		return 0, err
+	}
+	client, err := p.newClient(httpClient)
+	if err != nil {
+		return 0, err
+	}
+
+	org, err := client.Orgs.GetOrgByName(p.config.OrgName)
+	if err != nil {
+		return 0, fmt.Errorf("looking up Grafana organization %s: %w", p.config.OrgName, err)
+	}
+
+	p.orgID = org.Payload.ID
+	return p.orgID, nil
+}
+
+// ScopeMetadata implements grizzly.ScopedProvider: resources can select their
+// organization with metadata.org, by ID or name.
+func (p *Provider) ScopeMetadata() string {
+	return orgMetadata
+}
+
+// InScope implements grizzly.ScopedProvider. It returns a provider sending
+// requests to the given organization, with the same credentials.
+func (p *Provider) InScope(scope any) (grizzly.Provider, error) {
+	org := fmt.Sprint(scope)
+	if provider, ok := p.orgProviders[org]; ok {
+		return provider, nil
+	}
+
+	// credentials are resolved once, for all organizations
+	if err := p.config.ResolveCredentials(); err != nil {
+		return nil, err
+	}
+
+	orgConfig := *p.config
+	orgConfig.OrgID, orgConfig.OrgName = 0, ""
+	if id, err := strconv.ParseInt(org, 10, 64); err == nil {
+		orgConfig.OrgID = id
+	} else {
+		orgConfig.OrgName = org
+	}
+
+	if p.orgProviders == nil {
+		p.orgProviders = map[string]*Provider{}
+	}
+	provider := &Provider{
+		config:     &orgConfig,
+		httpConfig: p.httpConfig,
+		limiter:    p.limiter,
+	}
+	p.orgProviders[org] = provider
+
+	return provider, nil
 }
 
 func (p *Provider) Config() *config.GrafanaConfig {
@@ -131,17 +258,26 @@ func (p *Provider) APIVersion() string {
 // GetHandlers lists the resource handlers for the Grafana provider
 func (p *Provider) GetHandlers() []grizzly.Handler {
 	return []grizzly.Handler{
+		NewOrganizationHandler(p),
+		NewTeamHandler(p),
+		NewServiceAccountHandler(p),
 		NewDatasourceHandler(p),
 		NewFolderHandler(p),
 		NewLibraryElementHandler(p),
 		NewDashboardHandler(p),
+		NewFolderPermissionsHandler(p),
+		NewDashboardPermissionsHandler(p),
 		NewAlertRuleGroupHandler(p),
+		NewAlertMuteTimingHandler(p),
 		NewAlertNotificationPolicyHandler(p),
+		NewAlertNotificationPolicyRouteHandler(p),
 		NewAlertContactPointHandler(p),
 		NewAlertNotificationTemplateHandler(p),
 	}
 }
 
+var _ grizzly.ScopedProvider = (*Provider)(nil)
+
 func (p *Provider) SetupProxy() (*httputil.ReverseProxy, string, error) {
 	status := p.Status()
 	if !status.Active {
@@ -156,12 +292,20 @@ func (p *Provider) SetupProxy() (*httputil.ReverseProxy, string, error) {
 		return nil, "", err
 	}
 
+	orgID, err := p.OrgID()
+	if err != nil {
+		return nil, "", err
+	}
+
 	return &httputil.ReverseProxy{
 		Rewrite: func(r *httputil.ProxyRequest) {
 			u.Path = "" // to ensure possible sub-paths won't be added twice.
 			r.SetURL(u)
 
 			authenticateRequest(p.config, r.Out)
+			if orgID != 0 {
+				r.Out.Header.Set(gclient.OrgIDHeader, strconv.FormatInt(orgID, 10))
+			}
 
 			r.Out.Header.Del("Origin")
 			r.Out.Header.Set("User-Agent", "Grizzly Proxy Server")
@@ -0,0 +1,81 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderOrganizations(t *testing.T) {
+	var orgHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgHeaders = append(orgHeaders, r.Header.Get("X-Grafana-Org-Id"))
+		if r.URL.Path == "/api/orgs/name/customer" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 7, "name": "customer"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	get := func(t *testing.T, provider *Provider) string {
+		t.Helper()
+		orgHeaders = nil
+
+		client, err := provider.HTTPClient()
+		require.NoError(t, err)
+		resp, err := client.Get(server.URL + "/api/health")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		return orgHeaders[len(orgHeaders)-1]
+	}
+
+	t.Run("requests target the default organization", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+		require.Equal(t, "", get(t, provider))
+	})
+
+	t.Run("requests target the configured organization", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL, OrgID: 2}, &config.HTTPConfig{})
+		require.Equal(t, "2", get(t, provider))
+	})
+
+	t.Run("organizations can be configured by name", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL, OrgName: "customer"}, &config.HTTPConfig{})
+		require.Equal(t, "7", get(t, provider))
+		require.Equal(t, []string{"", "7"}, orgHeaders)
+	})
+
+	t.Run("resources select their organization", func(t *testing.T) {
+		provider := NewProvider(&config.GrafanaConfig{URL: server.URL, OrgID: 2}, &config.HTTPConfig{})
+		registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+		resource, err := grizzly.NewResource(provider.APIVersion(), "Dashboard", "dashboard", map[string]any{"uid": "dashboard"})
+		require.NoError(t, err)
+
+		handler, err := registry.GetHandlerFor(resource)
+		require.NoError(t, err)
+		require.Same(t, provider, handler.(*DashboardHandler).Provider)
+
+		resource.SetMetadata("org", "3")
+		handler, err = registry.GetHandlerFor(resource)
+		require.NoError(t, err)
+		scoped := handler.(*DashboardHandler).Provider.(*Provider)
+		require.Equal(t, "3", get(t, scoped))
+
+		again, err := registry.GetHandlerFor(resource)
+		require.NoError(t, err)
+		require.Same(t, handler, again)
+
+		resource.SetMetadata("org", "customer")
+		handler, err = registry.GetHandlerFor(resource)
+		require.NoError(t, err)
+		require.Equal(t, "7", get(t, handler.(*DashboardHandler).Provider.(*Provider)))
+	})
+}
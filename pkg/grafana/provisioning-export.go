@@ -0,0 +1,267 @@
+package grafana
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	provisioningDatasourcesFile = "datasources/datasources.yaml"
+	provisioningAlertingFile    = "alerting/alerting.yaml"
+)
+
+type provisioningFile struct {
+	APIVersion    int   `yaml:"apiVersion"`
+	Datasources   []any `yaml:"datasources,omitempty"`
+	Groups        []any `yaml:"groups,omitempty"`
+	ContactPoints []any `yaml:"contactPoints,omitempty"`
+	Policies      []any `yaml:"policies,omitempty"`
+	Templates     []any `yaml:"templates,omitempty"`
+	MuteTimes     []any `yaml:"muteTimes,omitempty"`
+}
+
+// ExportProvisioningFiles writes datasources and alerting resources in the
+// format of Grafana provisioning files, the one ParseDocument reads. It
+// returns the files to write, by path, and warnings about the resources
+// that couldn't be exported.
+func ExportProvisioningFiles(resources grizzly.Resources) (map[string][]byte, []string, error) {
+	datasources := provisioningFile{APIVersion: 1}
+	alerting := provisioningFile{APIVersion: 1}
+	var warnings []string
+
+	byOrg := map[int]grizzly.Resources{}
+	folderTitles := map[string]string{}
+	skipped := map[string]int{}
+	for _, resource := range sortedByName(resources.AsList()) {
+		if resource.Kind() == DashboardFolderKind {
+			title, _ := resource.GetSpecString("title")
+			folderTitles[resource.Name()] = title
+		}
+
+		orgID, ok := provisioningOrgID(resource)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s: organization %s must be referred to by ID, skipped", resource.Ref(), resource.GetMetadata(orgMetadata)))
+			continue
+		}
+
+		spec, secretWarnings := provisioningSpec(resource)
+		warnings = append(warnings, secretWarnings...)
+
+		switch resource.Kind() {
+		case DatasourceKind:
+			for _, key := range []string{"id", "version", "readOnly"} {
+				delete(spec, key)
+			}
+			spec["orgId"] = orgID
+			datasources.Datasources = append(datasources.Datasources, spec)
+		case KindAlertNotificationTemplate, KindAlertMuteTiming:
+			spec["orgId"] = orgID
+			if resource.Kind() == KindAlertNotificationTemplate {
+				alerting.Templates = append(alerting.Templates, spec)
+			} else {
+				alerting.MuteTimes = append(alerting.MuteTimes, spec)
+			}
+		case AlertContactPointKind, AlertNotificationPolicyKind, AlertNotificationPolicyRouteKind, AlertRuleGroupKind:
+			// grouped by organization first
+			if _, found := byOrg[orgID]; !found {
+				byOrg[orgID] = grizzly.NewResources()
+			}
+			exported, err := grizzly.NewResource(resource.APIVersion(), resource.Kind(), resource.Name(), spec)
+			if err != nil {
+				return nil, nil, err
+			}
+			byOrg[orgID].Add(exported)
+		case DashboardFolderKind:
+			// only exported as the folders of rule groups
+		default:
+			skipped[resource.Kind()]++
+		}
+	}
+
+	orgIDs := make([]int, 0, len(byOrg))
+	for orgID := range byOrg {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Ints(orgIDs)
+
+	for _, orgID := range orgIDs {
+		orgResources := byOrg[orgID]
+
+		for _, group := range orgResources.OfKind(AlertRuleGroupKind).AsList() {
+			item, warning := provisioningRuleGroup(group, folderTitles)
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
+			item["orgId"] = orgID
+			alerting.Groups = append(alerting.Groups, item)
+		}
+
+		contactPoints := map[string]map[string]any{}
+		for _, contactPoint := range orgResources.OfKind(AlertContactPointKind).AsList() {
+			receiver := contactPoint.Spec()
+			name, _ := receiver["name"].(string)
+			delete(receiver, "name")
+			item, found := contactPoints[name]
+			if !found {
+				item = map[string]any{"orgId": orgID, "name": name, "receivers": []any{}}
+				contactPoints[name] = item
+			}
+			item["receivers"] = append(item["receivers"].([]any), receiver)
+		}
+		for _, name := range sortedKeys(contactPoints) {
+			alerting.ContactPoints = append(alerting.ContactPoints, contactPoints[name])
+		}
+
+		policies := orgResources.OfKind(AlertNotificationPolicyKind).AsList()
+		routes := orgResources.OfKind(AlertNotificationPolicyRouteKind).AsList()
+		if len(policies) == 0 {
+			for _, route := range routes {
+				warnings = append(warnings, fmt.Sprintf("%s: provisioning files only hold whole notification policies, skipped as there's no %s", route.Ref(), AlertNotificationPolicyKind))
+			}
+			continue
+		}
+		tree, err := mergedPolicyTree(policies[0], routes)
+		if err != nil {
+			return nil, nil, err
+		}
+		policy, err := structToMap(tree)
+		if err != nil {
+			return nil, nil, err
+		}
+		removeEmptyRouteFields(policy)
+		policy["orgId"] = orgID
+		alerting.Policies = append(alerting.Policies, policy)
+	}
+
+	for _, kind := range sortedKeys(skipped) {
+		warnings = append(warnings, fmt.Sprintf("%d %s resources have no provisioning file equivalent, skipped", skipped[kind], kind))
+	}
+
+	files := map[string][]byte{}
+	if len(datasources.Datasources) != 0 {
+		content, err := yaml.Marshal(datasources)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[provisioningDatasourcesFile] = content
+	}
+	if len(alerting.Groups)+len(alerting.ContactPoints)+len(alerting.Policies)+len(alerting.Templates)+len(alerting.MuteTimes) != 0 {
+		content, err := yaml.Marshal(alerting)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[provisioningAlertingFile] = content
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no datasources nor alerting resources to export")
+	}
+	return files, warnings, nil
+}
+
+// provisioningRuleGroup returns a rule group as written in provisioning
+// files, which refer to folders by title
+func provisioningRuleGroup(group grizzly.Resource, folderTitles map[string]string) (map[string]any, string) {
+	spec := group.Spec()
+	folderUID, _ := spec["folderUid"].(string)
+	item := map[string]any{
+		"name":      spec["title"],
+		"folderUid": folderUID,
+	}
+
+	warning := ""
+	if title, found := folderTitles[folderUID]; found {
+		item["folder"] = title
+	} else {
+		item["folder"] = folderUID
+		warning = fmt.Sprintf("%s: folder %s isn't exported, its UID is written as its title", group.Ref(), folderUID)
+	}
+
+	if interval, ok := spec["interval"]; ok {
+		item["interval"] = fmt.Sprintf("%vs", interval)
+	}
+
+	item["rules"] = exportedAlertRules(spec)
+
+	return item, warning
+}
+
+// exportedAlertRules returns the rules of a rule group without the fields
+// held by the group itself or set by Grafana, as written outside of the API
+func exportedAlertRules(group map[string]any) []any {
+	rules, _ := group["rules"].([]any)
+	exported := make([]any, 0, len(rules))
+	for _, value := range rules {
+		rule, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		rule = withoutEmptyValues(rule)
+		for _, key := range []string{"folderUID", "ruleGroup", "orgID", "id", "updated", "provenance"} {
+			delete(rule, key)
+		}
+		exported = append(exported, rule)
+	}
+	return exported
+}
+
+// provisioningSpec returns the spec of resource, in which secret references
+// to environment variables and files are replaced by the expressions
+// Grafana reads them with. Other secrets can't be read by Grafana, they're
+// left out.
+func provisioningSpec(resource grizzly.Resource) (map[string]any, []string) {
+	baseDir := ""
+	if resource.Source.Path != "" {
+		baseDir = filepath.Dir(resource.Source.Path)
+	}
+
+	var leftOut []string
+	spec := provisioningSecrets(resource.Spec(), "", baseDir, &leftOut).(map[string]any)
+
+	var warnings []string
+	for _, path := range leftOut {
+		warnings = append(warnings, fmt.Sprintf("%s: secret %s isn't read from an environment variable nor a file, left out", resource.Ref(), path))
+	}
+	return spec, warnings
+}
+
+func provisioningSecrets(value any, path string, baseDir string, leftOut *[]string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if reference, ok := v[grizzly.SecretKey].(map[string]any); ok && len(v) == 1 {
+			if env, ok := reference["env"].(string); ok {
+				return "$__env{" + env + "}"
+			}
+			if file, ok := reference["file"].(string); ok {
+				if !filepath.IsAbs(file) {
+					file = filepath.Join(baseDir, file)
+				}
+				return "$__file{" + file + "}"
+			}
+			*leftOut = append(*leftOut, path)
+			return nil
+		}
+		result := make(map[string]any, len(v))
+		for _, key := range sortedKeys(v) {
+			item := provisioningSecrets(v[key], strings.TrimPrefix(path+"."+key, "."), baseDir, leftOut)
+			if item != nil || v[key] == nil {
+				result[key] = item
+			}
+		}
+		return result
+	case []any:
+		result := make([]any, 0, len(v))
+		for i, item := range v {
+			item := provisioningSecrets(item, fmt.Sprintf("%s[%d]", path, i), baseDir, leftOut)
+			if item != nil || v[i] == nil {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,384 @@
+package grafana
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	log "github.com/sirupsen/logrus"
+)
+
+var _ grizzly.DocumentProvider = &Provider{}
+
+// defaultOrgID is the organization resources of provisioning files belong to
+// when they don't specify one.
+const defaultOrgID = 1
+
+var (
+	// provisioningEnvRegexp matches values Grafana reads from environment
+	// variables, e.g. $__env{TOKEN}, ${TOKEN} or $TOKEN
+	provisioningEnvRegexp = regexp.MustCompile(`^\$(?:__env\{(\w+)\}|\{(\w+)\}|(\w+))$`)
+	// provisioningFileRegexp matches values Grafana reads from files, e.g.
+	// $__file{/etc/secrets/token}
+	provisioningFileRegexp = regexp.MustCompile(`^\$__file\{(.+)\}$`)
+
+	provisioningMatcherRegexp = regexp.MustCompile(`^\s*("(?:[^"\\]|\\.)*"|[^\s=!~"]+)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+)
+
+// ParseDocument implements grizzly.DocumentProvider: it recognises Grafana
+// provisioning files (apiVersion: 1) and returns the datasources and
+// alerting resources they describe.
+func (p *Provider) ParseDocument(data map[string]any) (grizzly.Resources, bool, error) {
+	if !isProvisioningDocument(data) {
+		return grizzly.Resources{}, false, nil
+	}
+
+	sections := map[string]func(item map[string]any, orgID int) ([]grizzly.Resource, error){
+		"datasources":   p.provisionedDatasource,
+		"contactPoints": p.provisionedContactPoints,
+		"policies":      p.provisionedPolicy,
+		"groups":        p.provisionedRuleGroup,
+		"templates":     p.provisionedTemplate,
+		"muteTimes":     p.provisionedMuteTiming,
+	}
+
+	resources := grizzly.NewResources()
+	for _, section := range sortedKeys(data) {
+		if section == "apiVersion" {
+			continue
+		}
+		parse, supported := sections[section]
+		if !supported {
+			log.Warnf("provisioning section '%s' isn't supported, skipped", section)
+			continue
+		}
+
+		items, ok := data[section].([]any)
+		if !ok && data[section] != nil {
+			return grizzly.Resources{}, true, fmt.Errorf("provisioning section '%s' must be a list", section)
+		}
+		for i, item := range items {
+			location := fmt.Sprintf(".%s[%d]", section, i)
+			m, ok := item.(map[string]any)
+			if !ok {
+				return grizzly.Resources{}, true, fmt.Errorf("%s: expected an object", location)
+			}
+			orgID, err := provisionedOrgID(m)
+			if err != nil {
+				return grizzly.Resources{}, true, fmt.Errorf("%s: %w", location, err)
+			}
+			parsed, err := parse(m, orgID)
+			if err != nil {
+				return grizzly.Resources{}, true, fmt.Errorf("%s: %w", location, err)
+			}
+			for _, resource := range parsed {
+				resource.SetSource(grizzly.Source{Location: location})
+				resources.Add(resource)
+			}
+		}
+	}
+	return resources, true, nil
+}
+
+func isProvisioningDocument(data map[string]any) bool {
+	if _, hasKind := data["kind"]; hasKind {
+		return false
+	}
+	switch version := data["apiVersion"].(type) {
+	case int:
+		return version == 1
+	case float64:
+		return version == 1
+	case string:
+		return version == "1"
+	}
+	return false
+}
+
+func (p *Provider) provisionedDatasource(item map[string]any, orgID int) ([]grizzly.Resource, error) {
+	spec := withoutEmptyValues(item)
+	uid, _ := spec["uid"].(string)
+	if uid == "" {
+		return nil, fmt.Errorf("datasource %v has no uid", spec["name"])
+	}
+	// provisioning only fields, datasources are always editable with the API
+	for _, key := range []string{"orgId", "version", "editable"} {
+		delete(spec, key)
+	}
+
+	resource, err := p.provisionedResource(DatasourceKind, uid, provisionedSecrets(spec).(map[string]any), orgID)
+	if err != nil {
+		return nil, err
+	}
+	return []grizzly.Resource{resource}, nil
+}
+
+func (p *Provider) provisionedContactPoints(item map[string]any, orgID int) ([]grizzly.Resource, error) {
+	name, _ := item["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("contact point has no name")
+	}
+	receivers, _ := item["receivers"].([]any)
+
+	resources := make([]grizzly.Resource, 0, len(receivers))
+	for i, receiver := range receivers {
+		spec, ok := receiver.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("receiver %d of %s: expected an object", i, name)
+		}
+		spec = withoutEmptyValues(spec)
+		uid, _ := spec["uid"].(string)
+		if uid == "" {
+			return nil, fmt.Errorf("receiver %d of %s has no uid", i, name)
+		}
+		spec["name"] = name
+		if settings, ok := spec["settings"]; ok {
+			spec["settings"] = provisionedSecrets(settings)
+		}
+
+		resource, err := p.provisionedResource(AlertContactPointKind, uid, spec, orgID)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+func (p *Provider) provisionedPolicy(item map[string]any, orgID int) ([]grizzly.Resource, error) {
+	spec := withoutEmptyValues(item)
+	delete(spec, "orgId")
+	if err := provisionedMatchers(spec); err != nil {
+		return nil, err
+	}
+
+	resource, err := p.provisionedResource(AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName, spec, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return []grizzly.Resource{resource}, nil
+}
+
+func (p *Provider) provisionedRuleGroup(item map[string]any, orgID int) ([]grizzly.Resource, error) {
+	title, _ := item["name"].(string)
+	if title == "" {
+		return nil, fmt.Errorf("rule group has no name")
+	}
+
+	var resources []grizzly.Resource
+	folderTitle, _ := item["folder"].(string)
+	folderUID, _ := item["folderUid"].(string)
+	if folderUID == "" && folderTitle != "" {
+		// Grafana finds the folder by title, the UID of an existing folder
+		// can't be told from it
+		return nil, fmt.Errorf("rule group %s: folderUid of folder %q not specified", title, folderTitle)
+	}
+	if folderUID == "" {
+		return nil, fmt.Errorf("folder of rule group %s not specified", title)
+	}
+	if folderTitle != "" {
+		// the folder is created along with the rules
+		folder, err := p.provisionedResource(DashboardFolderKind, folderUID, map[string]any{"uid": folderUID, "title": folderTitle}, orgID)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, folder)
+	}
+
+	interval := defaultPrometheusEvaluationInterval
+	if value, ok := item["interval"].(string); ok {
+		parsed, err := parsePrometheusDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("interval of rule group %s: %w", title, err)
+		}
+		interval = parsed
+	}
+
+	items, _ := item["rules"].([]any)
+	rules := make([]any, 0, len(items))
+	for i, value := range items {
+		rule, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("rule %d of %s: expected an object", i, title)
+		}
+		rule = withoutEmptyValues(rule)
+		// durations are compared to the ones Grafana returns, e.g. 5m0s
+		if pending, ok := rule["for"].(string); ok {
+			parsed, err := parsePrometheusDuration(pending)
+			if err != nil {
+				return nil, fmt.Errorf("for of rule %v: %w", rule["title"], err)
+			}
+			rule["for"] = parsed.String()
+		}
+		rule["folderUID"] = folderUID
+		rule["ruleGroup"] = title
+		rule["orgID"] = orgID
+		rules = append(rules, rule)
+	}
+
+	spec := map[string]any{
+		"folderUid": folderUID,
+		"title":     title,
+		"interval":  int64(interval.Seconds()),
+		"rules":     rules,
+	}
+	group, err := p.provisionedResource(AlertRuleGroupKind, joinAlertRuleGroupUID(folderUID, title), spec, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return append(resources, group), nil
+}
+
+func (p *Provider) provisionedTemplate(item map[string]any, orgID int) ([]grizzly.Resource, error) {
+	return p.provisionedNamedResource(KindAlertNotificationTemplate, item, "template", orgID)
+}
+
+func (p *Provider) provisionedMuteTiming(item map[string]any, orgID int) ([]grizzly.Resource, error) {
+	return p.provisionedNamedResource(KindAlertMuteTiming, item, "time_intervals", orgID)
+}
+
+// provisionedNamedResource returns a resource named after item, holding its
+// name and the given field
+func (p *Provider) provisionedNamedResource(kind string, item map[string]any, field string, orgID int) ([]grizzly.Resource, error) {
+	name, _ := item["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%s has no name", kind)
+	}
+	spec := map[string]any{"name": name}
+	if value, ok := item[field]; ok {
+		spec[field] = value
+	}
+
+	resource, err := p.provisionedResource(kind, name, spec, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return []grizzly.Resource{resource}, nil
+}
+
+// provisionedResource returns a resource of the given organization
+func (p *Provider) provisionedResource(kind, name string, spec map[string]any, orgID int) (grizzly.Resource, error) {
+	resource, err := grizzly.NewResource(p.APIVersion(), kind, name, spec)
+	if err != nil {
+		return resource, err
+	}
+	if orgID != defaultOrgID {
+		resource.SetMetadata(orgMetadata, strconv.Itoa(orgID))
+	}
+	return resource, nil
+}
+
+// provisionedOrgID returns the organization of an item of a provisioning
+// file
+func provisionedOrgID(item map[string]any) (int, error) {
+	if item["orgId"] == nil {
+		return defaultOrgID, nil
+	}
+	orgID, err := strconv.Atoi(fmt.Sprint(item["orgId"]))
+	if err != nil {
+		return 0, fmt.Errorf("orgId must be a number, got %v", item["orgId"])
+	}
+	return orgID, nil
+}
+
+// provisionedSecrets replaces the values Grafana reads from environment
+// variables or files with secret references.
+func provisionedSecrets(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = provisionedSecrets(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = provisionedSecrets(item)
+		}
+		return result
+	case string:
+		if matches := provisioningEnvRegexp.FindStringSubmatch(v); matches != nil {
+			return map[string]any{grizzly.SecretKey: map[string]any{"env": matches[1] + matches[2] + matches[3]}}
+		}
+		if matches := provisioningFileRegexp.FindStringSubmatch(v); matches != nil {
+			return map[string]any{grizzly.SecretKey: map[string]any{"file": matches[1]}}
+		}
+	}
+	return value
+}
+
+// provisionedMatchers turns the matchers of a route and its nested routes,
+// written as strings in provisioning files, into object_matchers.
+func provisionedMatchers(route map[string]any) error {
+	if matchers, ok := route["matchers"].([]any); ok {
+		objectMatchers, _ := route["object_matchers"].([]any)
+		for _, matcher := range matchers {
+			text, ok := matcher.(string)
+			if !ok {
+				return fmt.Errorf("matcher %v must be a string, e.g. severity=\"critical\"", matcher)
+			}
+			objectMatcher, err := parseProvisionedMatcher(text)
+			if err != nil {
+				return err
+			}
+			objectMatchers = append(objectMatchers, objectMatcher)
+		}
+		delete(route, "matchers")
+		route["object_matchers"] = objectMatchers
+	}
+
+	routes, _ := route["routes"].([]any)
+	for _, nested := range routes {
+		if nested, ok := nested.(map[string]any); ok {
+			if err := provisionedMatchers(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func parseProvisionedMatcher(text string) ([]any, error) {
+	matches := provisioningMatcherRegexp.FindStringSubmatch(text)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid matcher '%s'", text)
+	}
+	name, operator, value := matches[1], matches[2], matches[3]
+	for _, quoted := range []*string{&name, &value} {
+		if !strings.HasPrefix(*quoted, `"`) {
+			continue
+		}
+		unquoted, err := strconv.Unquote(*quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher '%s': %w", text, err)
+		}
+		*quoted = unquoted
+	}
+	return []any{name, operator, value}, nil
+}
+
+// withoutEmptyValues returns a copy of m without the keys left empty, as
+// provisioning files often hold them
+func withoutEmptyValues(m map[string]any) map[string]any {
+	result := make(map[string]any, len(m))
+	for key, value := range m {
+		if value != nil {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// provisioningOrgID returns the organization ID of resource, and whether
+// it is known: provisioning files can't refer to organizations by name
+func provisioningOrgID(resource grizzly.Resource) (int, bool) {
+	org := resource.GetMetadata(orgMetadata)
+	if org == "" {
+		return defaultOrgID, true
+	}
+	id, err := strconv.Atoi(org)
+	return id, err == nil
+}
@@ -0,0 +1,145 @@
+package grafana
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisioningFiles(t *testing.T) {
+	provider := NewProvider(&config.GrafanaConfig{}, &config.HTTPConfig{})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "provisioning.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`apiVersion: 1
+datasources:
+  - name: Prometheus
+    type: prometheus
+    uid: prom
+    orgId: 2
+    url: http://prometheus:9090
+    user:
+    secureJsonData:
+      basicAuthPassword: ${PROM_PASSWORD}
+groups:
+  - name: availability
+    folder: Platform Alerts
+    folderUid: platform-alerts
+    interval: 2m
+    rules:
+      - uid: target-down
+        title: TargetDown
+        condition: A
+        for: 5m
+contactPoints:
+  - name: platform
+    receivers:
+      - uid: platform-slack
+        type: slack
+        settings:
+          url: $__file{/etc/secrets/slack}
+      - uid: platform-email
+        type: email
+        settings:
+          addresses: platform@example.com
+policies:
+  - receiver: platform
+    routes:
+      - receiver: platform
+        matchers:
+          - severity =~ "critical|warning"
+templates:
+  - name: slack
+    template: '{{ define "slack" }}{{ end }}'
+`), 0644))
+
+	resources, err := grizzly.NewYAMLParser(registry).Parse(file, grizzly.ParserOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 7, resources.Len())
+
+	datasource, found := resources.Find(grizzly.NewResourceRef(DatasourceKind, "prom"))
+	require.True(t, found)
+	require.Equal(t, "2", datasource.GetMetadata(orgMetadata))
+	require.False(t, datasource.Source.Rewritable)
+	require.Equal(t, ".datasources[0]", datasource.Source.Location)
+	require.NotContains(t, datasource.Spec(), "user")
+	require.Equal(t, map[string]any{"basicAuthPassword": map[string]any{grizzly.SecretKey: map[string]any{"env": "PROM_PASSWORD"}}}, datasource.GetSpecValue("secureJsonData"))
+
+	folder, found := resources.Find(grizzly.NewResourceRef(DashboardFolderKind, "platform-alerts"))
+	require.True(t, found)
+	require.Equal(t, "Platform Alerts", folder.GetSpecValue("title"))
+
+	group, found := resources.Find(grizzly.NewResourceRef(AlertRuleGroupKind, "platform-alerts.availability"))
+	require.True(t, found)
+	require.NoError(t, NewAlertRuleGroupHandler(provider).Validate(group))
+	require.EqualValues(t, 120, group.GetSpecValue("interval"))
+	rule := group.GetSpecValue("rules").([]any)[0].(map[string]any)
+	require.Equal(t, "5m0s", rule["for"])
+	require.Equal(t, "availability", rule["ruleGroup"])
+
+	policy, found := resources.Find(grizzly.NewResourceRef(AlertNotificationPolicyKind, GlobalAlertNotificationPolicyName))
+	require.True(t, found)
+	route := policy.GetSpecValue("routes").([]any)[0].(map[string]any)
+	require.Equal(t, []any{[]any{"severity", "=~", "critical|warning"}}, route["object_matchers"])
+	require.NotContains(t, route, "matchers")
+
+	t.Run("resources are exported in the same format", func(t *testing.T) {
+		files, warnings, err := ExportProvisioningFiles(resources)
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+		require.Len(t, files, 2)
+
+		exportDir := t.TempDir()
+		for path, content := range files {
+			require.NoError(t, grizzly.WriteFile(filepath.Join(exportDir, path), content))
+		}
+		reparsed, err := grizzly.NewYAMLParser(registry).Parse(filepath.Join(exportDir, provisioningAlertingFile), grizzly.ParserOptions{})
+		require.NoError(t, err)
+
+		for _, resource := range reparsed.AsList() {
+			original, found := resources.Find(resource.Ref())
+			require.True(t, found, resource.Ref().String())
+			require.Equal(t, original.Spec(), resource.Spec(), resource.Ref().String())
+		}
+		require.Equal(t, 6, reparsed.Len())
+		require.Contains(t, string(files[provisioningDatasourcesFile]), "basicAuthPassword: $__env{PROM_PASSWORD}")
+	})
+
+	t.Run("secrets read from commands are left out", func(t *testing.T) {
+		withCommand, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", DatasourceKind, "vault", map[string]any{
+			"uid":  "vault",
+			"type": "postgres",
+			"secureJsonData": map[string]any{
+				"password": map[string]any{grizzly.SecretKey: map[string]any{"command": []any{"echo", "from-command"}}},
+			},
+		})
+		require.NoError(t, err)
+
+		files, warnings, err := ExportProvisioningFiles(grizzly.NewResources(withCommand))
+		require.NoError(t, err)
+		require.Equal(t, []string{"Datasource.vault: secret secureJsonData.password isn't read from an environment variable nor a file, left out"}, warnings)
+		require.NotContains(t, string(files[provisioningDatasourcesFile]), "password")
+		require.NotContains(t, string(files[provisioningDatasourcesFile]), "from-command")
+	})
+
+	t.Run("rule groups must give the UID of their folder", func(t *testing.T) {
+		_, _, err := provider.ParseDocument(map[string]any{
+			"apiVersion": 1,
+			"groups": []any{
+				map[string]any{"name": "availability", "folder": "Platform Alerts"},
+			},
+		})
+		require.EqualError(t, err, `.groups[0]: rule group availability: folderUid of folder "Platform Alerts" not specified`)
+	})
+
+	t.Run("other documents are left to other parsers", func(t *testing.T) {
+		_, isDocument, err := provider.ParseDocument(map[string]any{"apiVersion": "grizzly.grafana.com/v1alpha1", "kind": "Dashboard"})
+		require.NoError(t, err)
+		require.False(t, isDocument)
+	})
+}
@@ -0,0 +1,287 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-openapi-client-go/client/service_accounts"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const KindServiceAccount = "ServiceAccount"
+
+const serviceAccountPattern = "service-accounts/serviceAccount-%s.%s"
+
+var _ grizzly.Handler = &ServiceAccountHandler{}
+var _ grizzly.DeleteHandler = &ServiceAccountHandler{}
+
+// ServiceAccountHandler is a Grizzly Handler for Grafana service accounts.
+// Service accounts are identified by their name. Their tokens are not
+// resources: they are managed with CreateToken and RotateToken, so that they
+// never end up in resource files.
+type ServiceAccountHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewServiceAccountHandler returns a new Grizzly Handler for Grafana service accounts
+func NewServiceAccountHandler(provider grizzly.Provider) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, KindServiceAccount, false),
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *ServiceAccountHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(serviceAccountPattern, filename, filetype)
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *ServiceAccountHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("name") {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that spec.name, if set, matches the name of the resource
+func (h *ServiceAccountHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("spec.name '%s' and metadata.name '%s', don't match", name, resource.Name())
+	}
+	if disabled := resource.GetSpecValue("disabled"); disabled != nil {
+		if _, ok := disabled.(bool); !ok {
+			return fmt.Errorf("spec.disabled of %s must be a boolean", resource.Ref())
+		}
+	}
+	return nil
+}
+
+func (h *ServiceAccountHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, ok := resource.GetSpecString("name")
+	if !ok {
+		return "", fmt.Errorf("name not specified")
+	}
+	return name, nil
+}
+
+// GetByUID retrieves a service account by name
+func (h *ServiceAccountHandler) GetByUID(name string) (*grizzly.Resource, error) {
+	serviceAccount, err := h.getServiceAccount(name)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := map[string]any{
+		"name": serviceAccount.Name,
+		"role": serviceAccount.Role,
+	}
+	if serviceAccount.IsDisabled {
+		spec["disabled"] = true
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), serviceAccount.Name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// GetRemote retrieves a service account as a Resource
+func (h *ServiceAccountHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+// ListRemote retrieves a list of sorted names of all remote service accounts
+func (h *ServiceAccountHandler) ListRemote() ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	serviceAccounts, err := listServiceAccounts(client)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(serviceAccounts))
+	for _, name := range serviceAccounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Add creates a service account via the API
+func (h *ServiceAccountHandler) Add(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	role, _ := resource.GetSpecString("role")
+	disabled, _ := resource.GetSpecValue("disabled").(bool)
+	params := service_accounts.NewCreateServiceAccountParams().WithBody(&models.CreateServiceAccountForm{
+		Name:       resource.Name(),
+		Role:       role,
+		IsDisabled: disabled,
+	})
+	_, err = client.ServiceAccounts.CreateServiceAccount(params)
+	return err
+}
+
+// Update updates a service account via the API
+func (h *ServiceAccountHandler) Update(existing, resource grizzly.Resource) error {
+	serviceAccount, err := h.getServiceAccount(existing.Name())
+	if err != nil {
+		return err
+	}
+
+	// The API client omits false values: the request is sent by hand for
+	// disabled service accounts to be enabled again.
+	role, _ := resource.GetSpecString("role")
+	disabled, _ := resource.GetSpecValue("disabled").(bool)
+	body, err := json.Marshal(map[string]any{
+		"name":       resource.Name(),
+		"role":       role,
+		"isDisabled": disabled,
+	})
+	if err != nil {
+		return err
+	}
+
+	provider := h.Provider.(ClientProvider)
+	httpClient, err := provider.HTTPClient()
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/serviceaccounts/%d", strings.TrimSuffix(provider.Config().URL, "/"), serviceAccount.ID)
+	request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	authenticateRequest(provider.Config(), request)
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("updating service account %s: unexpected status %s", resource.Name(), response.Status)
+	}
+	return nil
+}
+
+// Delete deletes a service account and its tokens via the API
+func (h *ServiceAccountHandler) Delete(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	serviceAccount, err := getServiceAccountByName(client, resource.Name())
+	if err != nil {
+		return err
+	}
+
+	_, err = client.ServiceAccounts.DeleteServiceAccount(serviceAccount.ID)
+	return err
+}
+
+// CreateToken creates a token for a service account, valid for the given
+// duration (0 for a token that doesn't expire), and returns its key.
+func (h *ServiceAccountHandler) CreateToken(serviceAccountName, tokenName string, ttl time.Duration) (string, error) {
+	serviceAccount, err := h.getServiceAccount(serviceAccountName)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := h.createToken(serviceAccount.ID, tokenName, ttl)
+	if err != nil {
+		return "", err
+	}
+	return token.Key, nil
+}
+
+// RotateToken replaces the tokens of a service account created by CreateToken
+// or RotateToken with tokenName. The new token is handed to store before the
+// old ones are revoked, so that they keep working if it can't be stored.
+func (h *ServiceAccountHandler) RotateToken(serviceAccountName, tokenName string, ttl time.Duration, store func(key string) error) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	serviceAccount, err := h.getServiceAccount(serviceAccountName)
+	if err != nil {
+		return err
+	}
+
+	tokensOk, err := client.ServiceAccounts.ListTokens(serviceAccount.ID)
+	if err != nil {
+		return err
+	}
+
+	// token names are unique per service account
+	token, err := h.createToken(serviceAccount.ID, fmt.Sprintf("%s-%d", tokenName, time.Now().Unix()), ttl)
+	if err != nil {
+		return err
+	}
+	if err := store(token.Key); err != nil {
+		return err
+	}
+
+	// tokens named after tokenName alone, or suffixed with the time they
+	// were rotated at
+	rotated := regexp.MustCompile("^" + regexp.QuoteMeta(tokenName) + `(-\d+)?$`)
+	for _, old := range tokensOk.GetPayload() {
+		if !rotated.MatchString(old.Name) {
+			continue
+		}
+		if _, err := client.ServiceAccounts.DeleteToken(old.ID, serviceAccount.ID); err != nil {
+			return fmt.Errorf("revoking token %s: %w", old.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (h *ServiceAccountHandler) createToken(serviceAccountID int64, name string, ttl time.Duration) (*models.NewAPIKeyResult, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	params := service_accounts.NewCreateTokenParams().
+		WithServiceAccountID(serviceAccountID).
+		WithBody(&models.AddServiceAccountTokenCommand{
+			Name:          name,
+			SecondsToLive: int64(ttl.Seconds()),
+		})
+	response, err := client.ServiceAccounts.CreateToken(params)
+	if err != nil {
+		return nil, err
+	}
+	return response.GetPayload(), nil
+}
+
+func (h *ServiceAccountHandler) getServiceAccount(name string) (*models.ServiceAccountDTO, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+	return getServiceAccountByName(client, name)
+}
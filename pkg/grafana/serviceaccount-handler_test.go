@@ -0,0 +1,103 @@
+package grafana
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAccountHandler(t *testing.T) {
+	serviceAccount := map[string]any{"id": 3, "name": "ci", "role": "Viewer", "isDisabled": true}
+	tokens := map[int64]string{1: "grizzly", 2: "other", 3: "grizzly-ci"}
+	var lastTokenID int64 = 3
+
+	server := newFakeGrafana(t, map[string]http.HandlerFunc{
+		"GET /api/serviceaccounts/search": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]any{"serviceAccounts": []any{serviceAccount}})
+		},
+		"PATCH /api/serviceaccounts/3": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			if !readJSON(t, r, &body) {
+				return
+			}
+			for key, value := range body {
+				serviceAccount[key] = value
+			}
+			writeJSON(w, map[string]any{})
+		},
+		"GET /api/serviceaccounts/3/tokens": func(w http.ResponseWriter, r *http.Request) {
+			list := []map[string]any{}
+			for id, name := range tokens {
+				list = append(list, map[string]any{"id": id, "name": name})
+			}
+			writeJSON(w, list)
+		},
+		"POST /api/serviceaccounts/3/tokens": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			if !readJSON(t, r, &body) {
+				return
+			}
+			assert.Equal(t, 3600.0, body["secondsToLive"])
+			lastTokenID++
+			tokens[lastTokenID] = body["name"].(string)
+			writeJSON(w, map[string]any{"id": lastTokenID, "name": body["name"], "key": "glsa_new"})
+		},
+		"DELETE /api/serviceaccounts/3/tokens/{id}": func(w http.ResponseWriter, r *http.Request) {
+			id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+			if !assert.NoError(t, err) {
+				return
+			}
+			delete(tokens, id)
+			writeJSON(w, map[string]any{})
+		},
+	})
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewServiceAccountHandler(provider)
+
+	t.Run("service accounts are pulled", func(t *testing.T) {
+		resource, err := handler.GetByUID("ci")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"name": "ci", "role": "Viewer", "disabled": true}, resource.Spec())
+	})
+
+	t.Run("service accounts are updated and enabled", func(t *testing.T) {
+		resource, err := grizzly.NewResource(provider.APIVersion(), KindServiceAccount, "ci", map[string]any{
+			"role": "Editor",
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 1, applyResources(t, registry, resource).EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, "Editor", serviceAccount["role"])
+		require.Equal(t, false, serviceAccount["isDisabled"])
+	})
+
+	t.Run("tokens are rotated once the new one is stored", func(t *testing.T) {
+		err := handler.RotateToken("ci", "grizzly", time.Hour, func(key string) error {
+			return errors.New("no space left")
+		})
+		require.ErrorContains(t, err, "no space left")
+		require.Len(t, tokens, 4)
+		require.Equal(t, "grizzly", tokens[1])
+
+		var stored string
+		err = handler.RotateToken("ci", "grizzly", time.Hour, func(key string) error {
+			stored = key
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "glsa_new", stored)
+		require.Len(t, tokens, 3)
+		require.Equal(t, "other", tokens[2])
+		require.Equal(t, "grizzly-ci", tokens[3])
+		require.Regexp(t, `^grizzly-\d+$`, tokens[5])
+	})
+}
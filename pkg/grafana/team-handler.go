@@ -0,0 +1,348 @@
+package grafana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	gclient "github.com/grafana/grafana-openapi-client-go/client"
+	"github.com/grafana/grafana-openapi-client-go/client/teams"
+	"github.com/grafana/grafana-openapi-client-go/client/users"
+	"github.com/grafana/grafana-openapi-client-go/models"
+	"github.com/grafana/grizzly/pkg/grizzly"
+)
+
+const KindTeam = "Team"
+
+const teamPattern = "teams/team-%s.%s"
+
+var _ grizzly.Handler = &TeamHandler{}
+
+// TeamHandler is a Grizzly Handler for Grafana teams. Teams are identified by
+// their name, and their members by login or email.
+type TeamHandler struct {
+	grizzly.BaseHandler
+}
+
+// NewTeamHandler returns a new Grizzly Handler for Grafana teams
+func NewTeamHandler(provider grizzly.Provider) *TeamHandler {
+	return &TeamHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, KindTeam, false),
+	}
+}
+
+// ResourceFilePath returns the location on disk where a resource should be updated
+func (h *TeamHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	filename := strings.ReplaceAll(resource.Name(), string(os.PathSeparator), "-")
+	return fmt.Sprintf(teamPattern, filename, filetype)
+}
+
+// Prepare gets a resource ready for dispatch to the remote endpoint
+func (h *TeamHandler) Prepare(existing *grizzly.Resource, resource grizzly.Resource) *grizzly.Resource {
+	if !resource.HasSpecString("name") {
+		resource.SetSpecString("name", resource.Name())
+	}
+	return &resource
+}
+
+// Validate checks that spec.name, if set, matches the name of the resource, and
+// that members are listed by login or email
+func (h *TeamHandler) Validate(resource grizzly.Resource) error {
+	name, exist := resource.GetSpecString("name")
+	if exist && name != resource.Name() {
+		return fmt.Errorf("spec.name '%s' and metadata.name '%s', don't match", name, resource.Name())
+	}
+	if _, err := teamMembers(resource); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *TeamHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	name, ok := resource.GetSpecString("name")
+	if !ok {
+		return "", fmt.Errorf("name not specified")
+	}
+	return name, nil
+}
+
+// GetByUID retrieves a team by name
+func (h *TeamHandler) GetByUID(name string) (*grizzly.Resource, error) {
+	return h.getTeam(name, nil)
+}
+
+// GetRemote retrieves a team as a Resource. Remote members are listed the way
+// the resource lists them: by login or email, in the same order.
+func (h *TeamHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	local, err := teamMembers(resource)
+	if err != nil {
+		return nil, err
+	}
+	return h.getTeam(resource.Name(), local)
+}
+
+func (h *TeamHandler) getTeam(name string, localMembers []string) (*grizzly.Resource, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := getTeamByName(client, name)
+	if err != nil {
+		return nil, err
+	}
+	teamID := strconv.FormatInt(team.ID, 10)
+
+	membersOk, err := client.Teams.GetTeamMembers(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferencesOk, err := client.Teams.GetTeamPreferences(teamID)
+	if err != nil {
+		return nil, err
+	}
+	preferences, err := structToMap(preferencesOk.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+
+	spec := map[string]any{
+		"name": team.Name,
+	}
+	if team.Email != "" {
+		spec["email"] = team.Email
+	}
+	if len(membersOk.GetPayload()) != 0 {
+		spec["members"] = alignTeamMembers(localMembers, membersOk.GetPayload())
+	}
+	if len(preferences) != 0 {
+		spec["preferences"] = preferences
+	}
+
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), team.Name, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// ListRemote retrieves a list of sorted names of all remote teams
+func (h *TeamHandler) ListRemote() ([]string, error) {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		page    int64 = 1
+		perPage int64 = 1000
+		names   []string
+	)
+	for {
+		params := teams.NewSearchTeamsParams().WithPage(&page).WithPerpage(&perPage)
+		response, err := client.Teams.SearchTeams(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, team := range response.GetPayload().Teams {
+			names = append(names, team.Name)
+		}
+		if int64(len(response.GetPayload().Teams)) < perPage {
+			break
+		}
+		page++
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Add creates a team, its members and preferences via the API
+func (h *TeamHandler) Add(resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	email, _ := resource.GetSpecString("email")
+	created, err := client.Teams.CreateTeam(&models.CreateTeamCommand{
+		Name:  resource.Name(),
+		Email: email,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.updateTeam(client, strconv.FormatInt(created.GetPayload().TeamID, 10), resource, nil, true)
+}
+
+// Update updates a team, its members and preferences via the API
+func (h *TeamHandler) Update(existing, resource grizzly.Resource) error {
+	client, err := h.Provider.(ClientProvider).Client()
+	if err != nil {
+		return err
+	}
+
+	team, err := getTeamByName(client, existing.Name())
+	if err != nil {
+		return err
+	}
+	teamID := strconv.FormatInt(team.ID, 10)
+
+	email, _ := resource.GetSpecString("email")
+	_, err = client.Teams.UpdateTeam(teamID, &models.UpdateTeamCommand{
+		Name:  resource.Name(),
+		Email: email,
+	})
+	if err != nil {
+		return err
+	}
+
+	membersOk, err := client.Teams.GetTeamMembers(teamID)
+	if err != nil {
+		return err
+	}
+
+	return h.updateTeam(client, teamID, resource, membersOk.GetPayload(), false)
+}
+
+// updateTeam synchronises the members and preferences of a team with those of
+// the resource.
+func (h *TeamHandler) updateTeam(client *gclient.GrafanaHTTPAPI, teamID string, resource grizzly.Resource, remoteMembers []*models.TeamMemberDTO, created bool) error {
+	members, err := teamMembers(resource)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make([]int64, 0, len(members))
+	for _, member := range members {
+		user, err := client.Users.GetUserByLoginOrEmail(member)
+		if err != nil {
+			var gErr *users.GetUserByLoginOrEmailNotFound
+			if errors.As(err, &gErr) {
+				return fmt.Errorf("team member %s: user not found", member)
+			}
+			return err
+		}
+		userIDs = append(userIDs, user.GetPayload().ID)
+	}
+
+	wanted := make(map[int64]bool, len(userIDs))
+	for _, userID := range userIDs {
+		wanted[userID] = true
+	}
+	current := make(map[int64]bool, len(remoteMembers))
+	for _, member := range remoteMembers {
+		current[member.UserID] = true
+		if !wanted[member.UserID] {
+			if _, err := client.Teams.RemoveTeamMember(member.UserID, teamID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, userID := range userIDs {
+		if current[userID] {
+			continue
+		}
+		if _, err := client.Teams.AddTeamMember(teamID, &models.AddTeamMemberCommand{UserID: userID}); err != nil {
+			return err
+		}
+		current[userID] = true
+	}
+
+	preferences := resource.GetSpecValue("preferences")
+	if preferences == nil && created {
+		// new teams have no preferences to reset
+		return nil
+	}
+
+	var prefs models.UpdatePrefsCmd
+	if preferences != nil {
+		raw, err := json.Marshal(preferences)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &prefs); err != nil {
+			return fmt.Errorf("invalid team preferences: %w", err)
+		}
+	}
+	_, err = client.Teams.UpdateTeamPreferences(teamID, &prefs)
+	return err
+}
+
+func getTeamByName(client *gclient.GrafanaHTTPAPI, name string) (*models.TeamDTO, error) {
+	params := teams.NewSearchTeamsParams().WithName(&name)
+	response, err := client.Teams.SearchTeams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range response.GetPayload().Teams {
+		if team.Name == name {
+			return team, nil
+		}
+	}
+	return nil, grizzly.ErrNotFound
+}
+
+// teamMembers returns the logins or emails listed in spec.members.
+func teamMembers(resource grizzly.Resource) ([]string, error) {
+	raw := resource.GetSpecValue("members")
+	if raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("spec.members of %s must be a list of logins or emails", resource.Ref())
+	}
+	members := make([]string, 0, len(items))
+	for _, item := range items {
+		member, ok := item.(string)
+		if !ok || member == "" {
+			return nil, fmt.Errorf("spec.members of %s must be a list of logins or emails", resource.Ref())
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// alignTeamMembers lists remote members as local lists them: members matching
+// a local entry by login or email come first, as written locally, followed by
+// the other members by login.
+func alignTeamMembers(local []string, remote []*models.TeamMemberDTO) []any {
+	matched := make(map[int64]bool, len(remote))
+	members := make([]any, 0, len(remote))
+
+	for _, entry := range local {
+		for _, member := range remote {
+			if matched[member.UserID] {
+				continue
+			}
+			if strings.EqualFold(member.Login, entry) || (member.Email != "" && strings.EqualFold(member.Email, entry)) {
+				matched[member.UserID] = true
+				members = append(members, entry)
+				break
+			}
+		}
+	}
+
+	var others []string
+	for _, member := range remote {
+		if !matched[member.UserID] {
+			others = append(others, member.Login)
+		}
+	}
+	sort.Strings(others)
+	for _, login := range others {
+		members = append(members, login)
+	}
+
+	return members
+}
@@ -0,0 +1,207 @@
+package grafana
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/config"
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTeam struct {
+	Name        string
+	Email       string
+	Members     map[int64]bool
+	Preferences map[string]any
+}
+
+type fakeUser struct {
+	ID    int64
+	Login string
+	Email string
+}
+
+// fakeTeamsAPI serves the teams API from memory.
+func fakeTeamsAPI(t *testing.T, teams map[int64]*fakeTeam, users []fakeUser) *httptest.Server {
+	t.Helper()
+
+	// team returns the team of the id of the request, if it exists
+	team := func(w http.ResponseWriter, r *http.Request) (*fakeTeam, bool) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if !assert.NoError(t, err) || teams[id] == nil {
+			writeNotFound(w)
+			return nil, false
+		}
+		return teams[id], true
+	}
+
+	return newFakeGrafana(t, map[string]http.HandlerFunc{
+		"GET /api/users/lookup": func(w http.ResponseWriter, r *http.Request) {
+			for _, user := range users {
+				if query := r.URL.Query().Get("loginOrEmail"); user.Login == query || user.Email == query {
+					writeJSON(w, map[string]any{"id": user.ID, "login": user.Login, "email": user.Email})
+					return
+				}
+			}
+			writeNotFound(w)
+		},
+		"POST /api/teams": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			if !readJSON(t, r, &body) {
+				return
+			}
+			id := int64(len(teams) + 1)
+			teams[id] = &fakeTeam{Name: body["name"], Email: body["email"], Members: map[int64]bool{}}
+			writeJSON(w, map[string]any{"teamId": id})
+		},
+		"GET /api/teams/search": func(w http.ResponseWriter, r *http.Request) {
+			list := []map[string]any{}
+			for id, team := range teams {
+				if name := r.URL.Query().Get("name"); name == "" || name == team.Name {
+					list = append(list, map[string]any{"id": id, "name": team.Name, "email": team.Email})
+				}
+			}
+			writeJSON(w, map[string]any{"teams": list})
+		},
+		"PUT /api/teams/{id}": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			if team, ok := team(w, r); ok && readJSON(t, r, &body) {
+				team.Name, team.Email = body["Name"], body["Email"]
+				writeJSON(w, map[string]any{})
+			}
+		},
+		"GET /api/teams/{id}/members": func(w http.ResponseWriter, r *http.Request) {
+			team, ok := team(w, r)
+			if !ok {
+				return
+			}
+			list := []map[string]any{}
+			for _, user := range users {
+				if team.Members[user.ID] {
+					list = append(list, map[string]any{"userId": user.ID, "login": user.Login, "email": user.Email})
+				}
+			}
+			writeJSON(w, list)
+		},
+		"POST /api/teams/{id}/members": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]int64
+			if team, ok := team(w, r); ok && readJSON(t, r, &body) {
+				team.Members[body["userId"]] = true
+				writeJSON(w, map[string]any{})
+			}
+		},
+		"DELETE /api/teams/{id}/members/{userID}": func(w http.ResponseWriter, r *http.Request) {
+			team, ok := team(w, r)
+			if !ok {
+				return
+			}
+			userID, err := strconv.ParseInt(r.PathValue("userID"), 10, 64)
+			if !assert.NoError(t, err) {
+				return
+			}
+			delete(team.Members, userID)
+			writeJSON(w, map[string]any{})
+		},
+		"GET /api/teams/{id}/preferences": func(w http.ResponseWriter, r *http.Request) {
+			if team, ok := team(w, r); ok {
+				writeJSON(w, team.Preferences)
+			}
+		},
+		"PUT /api/teams/{id}/preferences": func(w http.ResponseWriter, r *http.Request) {
+			var preferences map[string]any
+			if team, ok := team(w, r); ok && readJSON(t, r, &preferences) {
+				team.Preferences = map[string]any{}
+				for key, value := range preferences {
+					if value != nil {
+						team.Preferences[key] = value
+					}
+				}
+				writeJSON(w, map[string]any{})
+			}
+		},
+	})
+}
+
+func TestTeamHandler(t *testing.T) {
+	teams := map[int64]*fakeTeam{}
+	users := []fakeUser{
+		{ID: 1, Login: "alice", Email: "alice@example.com"},
+		{ID: 2, Login: "bob", Email: "bob@example.com"},
+	}
+	server := fakeTeamsAPI(t, teams, users)
+
+	provider := NewProvider(&config.GrafanaConfig{URL: server.URL}, &config.HTTPConfig{})
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	handler := NewTeamHandler(provider)
+
+	apply := func(t *testing.T, spec map[string]any) grizzly.Summary {
+		t.Helper()
+		resource, err := grizzly.NewResource(provider.APIVersion(), KindTeam, "platform", spec)
+		require.NoError(t, err)
+		return applyResources(t, registry, resource)
+	}
+
+	t.Run("teams are created with their members and preferences", func(t *testing.T) {
+		summary := apply(t, map[string]any{
+			"email":       "platform@example.com",
+			"members":     []any{"bob@example.com", "alice"},
+			"preferences": map[string]any{"theme": "dark"},
+		})
+		require.Equal(t, 1, summary.EventCounts[grizzly.ResourceAdded])
+		require.Equal(t, &fakeTeam{
+			Name:        "platform",
+			Email:       "platform@example.com",
+			Members:     map[int64]bool{1: true, 2: true},
+			Preferences: map[string]any{"theme": "dark"},
+		}, teams[1])
+	})
+
+	t.Run("members are compared by login or email", func(t *testing.T) {
+		summary := apply(t, map[string]any{
+			"name":        "platform",
+			"email":       "platform@example.com",
+			"members":     []any{"bob@example.com", "alice"},
+			"preferences": map[string]any{"theme": "dark"},
+		})
+		require.Equal(t, 1, summary.EventCounts[grizzly.ResourceNotChanged])
+	})
+
+	t.Run("teams are updated", func(t *testing.T) {
+		summary := apply(t, map[string]any{
+			"members": []any{"alice"},
+		})
+		require.Equal(t, 1, summary.EventCounts[grizzly.ResourceUpdated])
+		require.Equal(t, &fakeTeam{
+			Name:        "platform",
+			Members:     map[int64]bool{1: true},
+			Preferences: map[string]any{},
+		}, teams[1])
+	})
+
+	t.Run("teams are pulled by name", func(t *testing.T) {
+		names, err := handler.ListRemote()
+		require.NoError(t, err)
+		require.Equal(t, []string{"platform"}, names)
+
+		resource, err := handler.GetByUID("platform")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"name": "platform", "members": []any{"alice"}}, resource.Spec())
+
+		_, err = handler.GetByUID("unknown")
+		require.ErrorIs(t, err, grizzly.ErrNotFound)
+	})
+
+	t.Run("unknown members are reported", func(t *testing.T) {
+		resource, err := grizzly.NewResource(provider.APIVersion(), KindTeam, "platform", map[string]any{
+			"members": []any{"carol"},
+		})
+		require.NoError(t, err)
+		err = grizzly.Apply(registry, grizzly.NewResources(resource), false, grizzly.NewWriterRecorder(io.Discard, grizzly.EventToPlainText))
+		require.ErrorContains(t, err, "team member carol: user not found")
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"sort"
 
 	gclient "github.com/grafana/grafana-openapi-client-go/client"
 	"github.com/grafana/grafana-openapi-client-go/models"
@@ -52,6 +53,21 @@ func structToMap(s interface{}) (map[string]interface{}, error) {
 	return result, nil
 }
 
+// asJSONValue returns a value the way it would be decoded from JSON, for
+// values read from YAML files and from requests to be compared.
+func asJSONValue(value any) any {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var result any
+	if err := json.Unmarshal(jsonData, &result); err != nil {
+		return value
+	}
+	return result
+}
+
 func authenticateAndProxyHandler(s grizzly.Server, provider grizzly.Provider) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "text/html")
@@ -71,7 +87,7 @@ func authenticateAndProxyHandler(s grizzly.Server, provider grizzly.Provider) ht
 		authenticateRequest(cfg, req)
 		req.Header.Set("User-Agent", s.UserAgent)
 
-		client, err := httputils.NewHTTPClient()
+		client, err := provider.(ClientProvider).HTTPClient()
 		if err != nil {
 			httputils.Error(w, http.StatusText(http.StatusInternalServerError), err, http.StatusInternalServerError)
 			return
@@ -108,3 +124,33 @@ func authenticateRequest(config *config.GrafanaConfig, request *http.Request) {
 		request.Header.Set("Authorization", "Bearer "+config.Token)
 	}
 }
+
+// unmarshalSpec decodes the spec of resource into value, typically a model
+// of the Grafana API.
+func unmarshalSpec(resource grizzly.Resource, value any) error {
+	data, err := json.Marshal(resource.Spec())
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("parsing %s: %w", resource.Ref(), err)
+	}
+	return nil
+}
+
+func sortedByName(resources []grizzly.Resource) []grizzly.Resource {
+	sorted := append([]grizzly.Resource{}, resources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name() < sorted[j].Name()
+	})
+	return sorted
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
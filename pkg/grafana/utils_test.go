@@ -10,7 +10,7 @@ import (
 )
 
 func TestExtractFolderUID(t *testing.T) {
-	provider := NewProvider(&config.GrafanaConfig{URL: "http://localhost:3000"})
+	provider := NewProvider(&config.GrafanaConfig{URL: "http://localhost:3000"}, &config.HTTPConfig{})
 
 	client, err := provider.Client()
 	require.NoError(t, err)
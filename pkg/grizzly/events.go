@@ -25,6 +25,18 @@ const (
 	Error
 )
 
+func (severity EventSeverity) String() string {
+	switch severity {
+	case Info:
+		return "info"
+	case Notice:
+		return "notice"
+	case Error:
+		return "error"
+	}
+	return "unknown"
+}
+
 type EventType struct {
 	Severity      EventSeverity
 	ID            string
@@ -36,18 +48,30 @@ var (
 	ResourceNotChanged = EventType{ID: "resource-not-changed", Severity: Info, HumanReadable: "unchanged"}
 	ResourceNotFound   = EventType{ID: "resource-not-found", Severity: Info, HumanReadable: "not found"}
 	ResourceUpdated    = EventType{ID: "resource-updated", Severity: Notice, HumanReadable: "updated"}
+	ResourceChanged    = EventType{ID: "resource-changed", Severity: Notice, HumanReadable: "changed"}
 	ResourcePulled     = EventType{ID: "resource-pulled", Severity: Notice, HumanReadable: "pulled"}
+	ResourceRemoved    = EventType{ID: "resource-removed", Severity: Notice, HumanReadable: "removed"}
 	ResourceFailure    = EventType{ID: "resource-failure", Severity: Error, HumanReadable: "failed"}
 )
 
 type Event struct {
 	Type        EventType
 	ResourceRef string
-	Details     string
+	// ResourcePath is the path on disk of the file describing the resource, if any.
+	ResourcePath string
+	Details      string
+	// Diff is a unified diff between the remote and the local resource, if any.
+	Diff string
 }
 
 type EventFormatter func(event Event) string
 
+// EventsFlusher describes an events recorder that only produces its output
+// once every event has been recorded.
+type EventsFlusher interface {
+	Flush() error
+}
+
 func EventToPlainText(event Event) string {
 	if event.Details == "" {
 		return fmt.Sprintf("%s %s\n", event.ResourceRef, event.Type.HumanReadable)
@@ -80,6 +104,69 @@ func EventToColoredText(event Event) string {
 	return fmt.Sprintf("%s %s: %s\n", event.ResourceRef, eventType, event.Details)
 }
 
+type jsonEvent struct {
+	Type         string `json:"type"`
+	Severity     string `json:"severity"`
+	Status       string `json:"status"`
+	ResourceRef  string `json:"resource"`
+	ResourcePath string `json:"path,omitempty"`
+	Details      string `json:"details,omitempty"`
+	Diff         string `json:"diff,omitempty"`
+}
+
+// EventToJSON formats an event as a single line of JSON.
+func EventToJSON(event Event) string {
+	line, err := json.Marshal(jsonEvent{
+		Type:         event.Type.ID,
+		Severity:     event.Type.Severity.String(),
+		Status:       event.Type.HumanReadable,
+		ResourceRef:  event.ResourceRef,
+		ResourcePath: event.ResourcePath,
+		Details:      event.Details,
+		Diff:         event.Diff,
+	})
+	if err != nil {
+		return EventToPlainText(event)
+	}
+
+	return string(line) + "\n"
+}
+
+// EventToGitHubAnnotation formats an event as a GitHub Actions workflow
+// command, annotating the file describing the resource when it is known.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions
+func EventToGitHubAnnotation(event Event) string {
+	var command string
+	switch event.Type.Severity {
+	case Notice:
+		command = "notice"
+	case Error:
+		command = "error"
+	default:
+		return EventToPlainText(event)
+	}
+
+	properties := []string{"title=" + escapeGitHubProperty(event.ResourceRef)}
+	if event.ResourcePath != "" {
+		properties = append([]string{"file=" + escapeGitHubProperty(event.ResourcePath)}, properties...)
+	}
+
+	message := fmt.Sprintf("%s %s", event.ResourceRef, event.Type.HumanReadable)
+	if event.Details != "" {
+		message += ": " + event.Details
+	}
+
+	return fmt.Sprintf("::%s %s::%s\n", command, strings.Join(properties, ","), escapeGitHubData(message))
+}
+
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeGitHubData(s))
+}
+
 type Summary struct {
 	EventCounts map[EventType]int
 }
@@ -127,7 +214,7 @@ func (recorder *WriterRecorder) Summary() Summary {
 var _ EventsRecorder = (*WriterRecorder)(nil)
 
 type UsageRecorder struct {
-	wr       *WriterRecorder
+	wr       EventsRecorder
 	endpoint string
 }
 
@@ -141,7 +228,7 @@ func (u *UsageRecorder) Summary() Summary {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 	group, ctx := errgroup.WithContext(ctx)
-	for op, n := range u.wr.summary.EventCounts {
+	for op, n := range u.wr.Summary().EventCounts {
 		group.Go(func() error {
 			return u.reportUsage(ctx, op.ID, n)
 		})
@@ -154,6 +241,14 @@ func (u *UsageRecorder) Summary() Summary {
 	return u.wr.Summary()
 }
 
+// Flush implements EventsFlusher.
+func (u *UsageRecorder) Flush() error {
+	if flusher, ok := u.wr.(EventsFlusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
 func (u *UsageRecorder) reportUsage(ctx context.Context, op string, n int) error {
 	var buff bytes.Buffer
 	configHash, err := config.Hash()
@@ -190,7 +285,7 @@ func (u *UsageRecorder) reportUsage(ctx context.Context, op string, n int) error
 
 var _ EventsRecorder = (*UsageRecorder)(nil)
 
-func NewUsageRecorder(wr *WriterRecorder) *UsageRecorder {
+func NewUsageRecorder(wr EventsRecorder) *UsageRecorder {
 	return &UsageRecorder{
 		wr:       wr,
 		endpoint: "https://stats.grafana.org/grizzly-usage-report",
@@ -0,0 +1,115 @@
+package grizzly_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventToJSON(t *testing.T) {
+	line := grizzly.EventToJSON(grizzly.Event{
+		Type:         grizzly.ResourceFailure,
+		ResourceRef:  "Dashboard.abc",
+		ResourcePath: "dashboards/abc.yaml",
+		Details:      "boom",
+	})
+	require.True(t, bytes.HasSuffix([]byte(line), []byte("\n")))
+
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+	require.Equal(t, map[string]string{
+		"type":     "resource-failure",
+		"severity": "error",
+		"status":   "failed",
+		"resource": "Dashboard.abc",
+		"path":     "dashboards/abc.yaml",
+		"details":  "boom",
+	}, decoded)
+}
+
+func TestEventToGitHubAnnotation(t *testing.T) {
+	t.Run("errors are annotated on the resource file", func(t *testing.T) {
+		annotation := grizzly.EventToGitHubAnnotation(grizzly.Event{
+			Type:         grizzly.ResourceFailure,
+			ResourceRef:  "Dashboard.abc",
+			ResourcePath: "dir,with:chars/abc.yaml",
+			Details:      "line one\nline two 100%",
+		})
+		require.Equal(t, "::error file=dir%2Cwith%3Achars/abc.yaml,title=Dashboard.abc::Dashboard.abc failed: line one%0Aline two 100%25\n", annotation)
+	})
+
+	t.Run("notices without a path only carry a title", func(t *testing.T) {
+		annotation := grizzly.EventToGitHubAnnotation(grizzly.Event{
+			Type:        grizzly.ResourceAdded,
+			ResourceRef: "Dashboard.abc",
+		})
+		require.Equal(t, "::notice title=Dashboard.abc::Dashboard.abc added\n", annotation)
+	})
+
+	t.Run("info events are printed as plain text", func(t *testing.T) {
+		annotation := grizzly.EventToGitHubAnnotation(grizzly.Event{
+			Type:        grizzly.ResourceNotChanged,
+			ResourceRef: "Dashboard.abc",
+		})
+		require.Equal(t, "Dashboard.abc unchanged\n", annotation)
+	})
+}
+
+func TestJUnitRecorder(t *testing.T) {
+	var out bytes.Buffer
+	recorder := grizzly.NewJUnitRecorder(&out, "apply")
+
+	recorder.Record(grizzly.Event{Type: grizzly.ResourceAdded, ResourceRef: "Dashboard.added", ResourcePath: "added.yaml"})
+	recorder.Record(grizzly.Event{Type: grizzly.ResourceNotFound, ResourceRef: "Folder.missing"})
+	recorder.Record(grizzly.Event{Type: grizzly.ResourceNotChanged, ResourceRef: "Dashboard.failed"})
+	recorder.Record(grizzly.Event{Type: grizzly.ResourceFailure, ResourceRef: "Dashboard.failed", Details: "boom"})
+
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceFailure])
+	require.NoError(t, recorder.Flush())
+
+	var report struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Skipped  int `xml:"skipped,attr"`
+		Suites   []struct {
+			Name      string `xml:"name,attr"`
+			TestCases []struct {
+				Name      string `xml:"name,attr"`
+				ClassName string `xml:"classname,attr"`
+				File      string `xml:"file,attr"`
+				Failure   *struct {
+					Message string `xml:"message,attr"`
+					Content string `xml:",chardata"`
+				} `xml:"failure"`
+				Skipped *struct{} `xml:"skipped"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &report))
+
+	require.Equal(t, 3, report.Tests)
+	require.Equal(t, 1, report.Failures)
+	require.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Suites, 1)
+	require.Equal(t, "apply", report.Suites[0].Name)
+
+	cases := report.Suites[0].TestCases
+	require.Len(t, cases, 3)
+
+	require.Equal(t, "Dashboard.added", cases[0].Name)
+	require.Equal(t, "Dashboard", cases[0].ClassName)
+	require.Equal(t, "added.yaml", cases[0].File)
+	require.Nil(t, cases[0].Failure)
+
+	require.Equal(t, "Folder.missing", cases[1].Name)
+	require.NotNil(t, cases[1].Skipped)
+
+	require.Equal(t, "Dashboard.failed", cases[2].Name)
+	require.NotNil(t, cases[2].Failure)
+	require.Equal(t, "failed", cases[2].Failure.Message)
+	require.Equal(t, "boom", cases[2].Failure.Content)
+}
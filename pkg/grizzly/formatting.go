@@ -2,6 +2,7 @@ package grizzly
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -15,6 +16,14 @@ const (
 	formatDefault = "default"
 )
 
+const (
+	// LayoutDefault writes resources at the location defined by their handler.
+	LayoutDefault = "default"
+	// LayoutFolders writes resources in a directory tree mirroring the remote
+	// folder hierarchy, for the handlers supporting it.
+	LayoutFolders = "folders"
+)
+
 func Format(registry Registry, resourcePath string, resource *Resource, format string, onlySpec bool) ([]byte, string, string, error) {
 	var content []byte
 	var filename string
@@ -57,6 +66,29 @@ func getFilename(registry Registry, resourcePath string, resource *Resource, ext
 	return filepath.Join(resourcePath, handler.ResourceFilePath(*resource, extension)), nil
 }
 
+func getLayoutFilename(registry Registry, resourcePath string, resource *Resource, extension string, layout string) (string, error) {
+	switch layout {
+	case "", LayoutDefault:
+		return getFilename(registry, resourcePath, resource, extension)
+	case LayoutFolders:
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			return "", err
+		}
+		hierarchicalHandler, ok := handler.(HierarchicalHandler)
+		if !ok {
+			return getFilename(registry, resourcePath, resource, extension)
+		}
+		path, err := hierarchicalHandler.HierarchicalFilePath(*resource, extension)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(resourcePath, path), nil
+	}
+
+	return "", fmt.Errorf("unknown layout: %s", layout)
+}
+
 func WriteFile(filename string, content []byte) error {
 	dir := filepath.Dir(filename)
 	err := os.MkdirAll(dir, 0755)
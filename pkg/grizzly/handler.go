@@ -105,6 +105,22 @@ type SnapshotHandler interface {
 	Snapshot(resource Resource, expiresSeconds int) error
 }
 
+// DeleteHandler describes a handler that has the ability to delete a remote
+// resource
+type DeleteHandler interface {
+	// Delete deletes the remote equivalent of a resource
+	Delete(resource Resource) error
+}
+
+// HierarchicalHandler describes a handler that can lay out the resources it
+// manages in a directory tree mirroring their remote hierarchy (ex: nested
+// folders in Grafana)
+type HierarchicalHandler interface {
+	// HierarchicalFilePath returns the location on disk where a resource should
+	// be written when mirroring the remote hierarchy
+	HierarchicalFilePath(resource Resource, filetype string) (string, error)
+}
+
 // ListenHandler describes a handler that has the ability to watch a single
 // resource for changes, and write changes to that resource to a local file
 type ListenHandler interface {
@@ -0,0 +1,136 @@
+package grizzly
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// JUnitRecorder records events and writes them as a JUnit XML report, with
+// one test case per resource, once flushed.
+type JUnitRecorder struct {
+	out       io.Writer
+	suiteName string
+	refs      []string
+	events    map[string]Event
+	summary   *Summary
+}
+
+var _ EventsRecorder = (*JUnitRecorder)(nil)
+var _ EventsFlusher = (*JUnitRecorder)(nil)
+
+func NewJUnitRecorder(out io.Writer, suiteName string) *JUnitRecorder {
+	return &JUnitRecorder{
+		out:       out,
+		suiteName: suiteName,
+		events:    make(map[string]Event),
+		summary: &Summary{
+			EventCounts: make(map[EventType]int),
+		},
+	}
+}
+
+// Record implements EventsRecorder.
+// Only the most severe event recorded for a resource is kept.
+func (recorder *JUnitRecorder) Record(event Event) {
+	recorder.summary.EventCounts[event.Type] += 1
+
+	existing, seen := recorder.events[event.ResourceRef]
+	if !seen {
+		recorder.refs = append(recorder.refs, event.ResourceRef)
+	}
+	if !seen || event.Type.Severity >= existing.Type.Severity {
+		recorder.events[event.ResourceRef] = event
+	}
+}
+
+// Summary implements EventsRecorder.
+func (recorder *JUnitRecorder) Summary() Summary {
+	return *recorder.summary
+}
+
+// Flush implements EventsFlusher.
+func (recorder *JUnitRecorder) Flush() error {
+	suite := junitTestSuite{
+		Name:      recorder.suiteName,
+		TestCases: make([]junitTestCase, 0, len(recorder.refs)),
+	}
+
+	for _, ref := range recorder.refs {
+		event := recorder.events[ref]
+		kind, _, _ := strings.Cut(ref, ".")
+
+		testCase := junitTestCase{
+			Name:      ref,
+			ClassName: kind,
+			File:      event.ResourcePath,
+			SystemOut: event.Type.HumanReadable,
+		}
+
+		switch {
+		case event.Type.Severity == Error:
+			testCase.Failure = &junitMessage{Message: event.Type.HumanReadable, Content: event.Details}
+			suite.Failures++
+		case event.Type == ResourceNotFound:
+			testCase.Skipped = &junitMessage{Message: event.Type.HumanReadable, Content: event.Details}
+			suite.Skipped++
+		case event.Details != "":
+			testCase.SystemOut += ": " + event.Details
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	report := junitTestSuites{
+		Name:     "grizzly",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(recorder.out, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(recorder.out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(recorder.out, "\n")
+	return err
+}
@@ -0,0 +1,37 @@
+package grizzly
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxKubernetesNameLength is the maximum length of the names of Kubernetes
+// objects, as DNS subdomains
+const maxKubernetesNameLength = 253
+
+var kubernetesNameInvalidCharacters = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// KubernetesManifest describes a Kubernetes object, such as the custom
+// resources of operators resources are exported to.
+type KubernetesManifest struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   KubernetesMetadata `yaml:"metadata"`
+	Spec       map[string]any     `yaml:"spec"`
+}
+
+// KubernetesMetadata holds the metadata of a Kubernetes object
+type KubernetesMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// KubernetesName turns name into a valid Kubernetes object name, e.g.
+// "Platform_Alerts" becomes "platform-alerts".
+func KubernetesName(name string) string {
+	name = kubernetesNameInvalidCharacters.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > maxKubernetesNameLength {
+		name = name[:maxKubernetesNameLength]
+	}
+	return strings.Trim(name, ".-")
+}
@@ -40,6 +40,7 @@ type Parser interface {
 
 type parsersConfig struct {
 	continueOnError bool
+	sopsAgeKeyFile  string
 }
 
 type ParserOpt func(config *parsersConfig)
@@ -50,6 +51,14 @@ func ParserContinueOnError(continueOnError bool) ParserOpt {
 	}
 }
 
+// ParserSOPSAgeKeyFile sets the file holding the age keys used to decrypt
+// SOPS-encrypted files.
+func ParserSOPSAgeKeyFile(ageKeyFile string) ParserOpt {
+	return func(config *parsersConfig) {
+		config.sopsAgeKeyFile = ageKeyFile
+	}
+}
+
 func DefaultParser(registry Registry, targets []string, jsonnetPaths []string, opts ...ParserOpt) Parser {
 	config := &parsersConfig{}
 
@@ -60,6 +69,7 @@ func DefaultParser(registry Registry, targets []string, jsonnetPaths []string, o
 	return NewFilteredParser(
 		registry,
 		NewChainParser([]FormatParser{
+			NewSOPSParser(registry, config.sopsAgeKeyFile),
 			NewJSONParser(registry),
 			NewYAMLParser(registry),
 			NewJsonnetParser(registry, jsonnetPaths),
@@ -202,6 +212,23 @@ func parseAny(registry Registry, data any, resourceKind, folderUID string, sourc
 		}
 		return resources, nil
 	}
+	documentResources, isDocument, err := registry.ParseDocument(data)
+	if err != nil {
+		return Resources{}, err
+	}
+	if isDocument {
+		// written back, resources would lose the format of the document
+		source.Rewritable = false
+		resources := NewResources()
+		for _, resource := range documentResources.AsList() {
+			resourceSource := source
+			resourceSource.Location = resource.Source.Location
+			resource.SetSource(resourceSource)
+			resources.Add(resource)
+		}
+		return resources, nil
+	}
+
 	hasEnvelope := DetectEnvelope(data)
 	if hasEnvelope {
 		m := data.(map[string]any)
@@ -261,7 +288,7 @@ func parseAny(registry Registry, data any, resourceKind, folderUID string, sourc
 		source:    source,
 		resources: NewResources(),
 	}
-	err := walker.Walk(data)
+	err = walker.Walk(data)
 
 	return walker.resources, err
 }
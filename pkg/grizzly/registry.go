@@ -37,18 +37,45 @@ type ProxyProvider interface {
 	SetupProxy() (*httputil.ReverseProxy, string, error)
 }
 
+// ScopedProvider is implemented by providers managing resources that live in
+// distinct scopes of the same remote system, such as Grafana organizations.
+// The scope of a resource is given by one of its metadata.
+type ScopedProvider interface {
+	Provider
+
+	// ScopeMetadata returns the metadata key holding the scope of resources.
+	ScopeMetadata() string
+	// InScope returns the provider managing the resources of the given scope.
+	InScope(scope any) (Provider, error)
+}
+
+// DocumentProvider is implemented by providers recognising documents in a
+// format of their own, such as Grafana provisioning files, among the
+// documents of YAML and JSON files.
+type DocumentProvider interface {
+	// ParseDocument returns the resources described by data, and whether
+	// data is a document of the provider's format.
+	ParseDocument(data map[string]any) (Resources, bool, error)
+}
+
 // Registry records providers
 type Registry struct {
 	Providers    []Provider
 	Handlers     map[string]Handler
 	HandlerOrder []Handler
+
+	kindProviders map[string]Provider
+	// scopedHandlers are the handlers of the providers of scopes, by kind.
+	scopedHandlers map[Provider]map[string]Handler
 }
 
 // NewRegistry returns an empty registry
 func NewRegistry(providers []Provider) Registry {
 	registry := Registry{
-		Handlers:     map[string]Handler{},
-		HandlerOrder: []Handler{},
+		Handlers:       map[string]Handler{},
+		HandlerOrder:   []Handler{},
+		kindProviders:  map[string]Provider{},
+		scopedHandlers: map[Provider]map[string]Handler{},
 	}
 
 	registry.Providers = providers
@@ -56,6 +83,7 @@ func NewRegistry(providers []Provider) Registry {
 		for _, handler := range provider.GetHandlers() {
 			registry.Handlers[handler.Kind()] = handler
 			registry.HandlerOrder = append(registry.HandlerOrder, handler)
+			registry.kindProviders[handler.Kind()] = provider
 		}
 	}
 	return registry
@@ -70,6 +98,72 @@ func (r *Registry) GetHandler(kind string) (Handler, error) {
 	return handler, nil
 }
 
+// GetHandlerFor returns the handler managing the given resource. Resources
+// of scoped providers are managed by the handler of their scope.
+func (r *Registry) GetHandlerFor(resource Resource) (Handler, error) {
+	handler, err := r.GetHandler(resource.Kind())
+	if err != nil {
+		return nil, err
+	}
+
+	scope, provider, ok := r.scopeOf(resource)
+	if !ok {
+		return handler, nil
+	}
+
+	scoped, err := provider.InScope(scope)
+	if err != nil {
+		return nil, fmt.Errorf("resolving scope of %s: %w", resource.Ref(), err)
+	}
+	if scopedHandler, ok := r.handlersOf(scoped)[handler.Kind()]; ok {
+		return scopedHandler, nil
+	}
+
+	return handler, nil
+}
+
+// handlersOf returns the handlers of the provider of a scope, by kind. They
+// are built once per scope.
+func (r *Registry) handlersOf(scoped Provider) map[string]Handler {
+	if handlers, ok := r.scopedHandlers[scoped]; ok {
+		return handlers
+	}
+
+	handlers := map[string]Handler{}
+	for _, handler := range scoped.GetHandlers() {
+		handlers[handler.Kind()] = handler
+	}
+	if r.scopedHandlers == nil {
+		r.scopedHandlers = map[Provider]map[string]Handler{}
+	}
+	r.scopedHandlers[scoped] = handlers
+	return handlers
+}
+
+// copyScope sets the scope of resource on remote, its counterpart read from
+// the scope's handler, which doesn't know about it.
+func (r *Registry) copyScope(resource Resource, remote *Resource) {
+	scope, provider, ok := r.scopeOf(resource)
+	if !ok {
+		return
+	}
+	remote.metadata()[provider.ScopeMetadata()] = scope
+}
+
+func (r *Registry) scopeOf(resource Resource) (any, ScopedProvider, bool) {
+	provider, ok := r.kindProviders[resource.Kind()].(ScopedProvider)
+	if !ok {
+		return nil, nil, false
+	}
+
+	scope, ok := resource.metadata()[provider.ScopeMetadata()]
+	if !ok || scope == nil || scope == "" {
+		return nil, nil, false
+	}
+
+	return scope, provider, true
+}
+
 // HandlerMatchesTarget identifies whether a handler is in a target list
 func (r *Registry) HandlerMatchesTarget(handler Handler, targets []string) bool {
 	if len(targets) == 0 {
@@ -139,6 +233,26 @@ func (r *Registry) Detect(data any) string {
 	return ""
 }
 
+// ParseDocument returns the resources described by data, if a provider
+// recognises it as a document of its own format.
+func (r *Registry) ParseDocument(data any) (Resources, bool, error) {
+	m, ok := data.(map[string]any)
+	if !ok {
+		return Resources{}, false, nil
+	}
+	for _, provider := range r.Providers {
+		documentProvider, ok := provider.(DocumentProvider)
+		if !ok {
+			continue
+		}
+		resources, ok, err := documentProvider.ParseDocument(m)
+		if ok || err != nil {
+			return resources, ok, err
+		}
+	}
+	return Resources{}, false, nil
+}
+
 func (r *Registry) GetProxyProvider() (*ProxyProvider, error) {
 	var proxyProvider *ProxyProvider
 	for _, provider := range r.Providers {
@@ -0,0 +1,188 @@
+package grizzly
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ReportRecorder records events and writes them as a Markdown report, grouped
+// by kind, once flushed. Events are also forwarded to a wrapped recorder.
+type ReportRecorder struct {
+	wr       EventsRecorder
+	out      io.Writer
+	title    string
+	registry Registry
+	baseURL  string
+
+	kinds   []string
+	events  map[string][]Event
+	summary *Summary
+}
+
+var _ EventsRecorder = (*ReportRecorder)(nil)
+var _ EventsFlusher = (*ReportRecorder)(nil)
+
+// NewReportRecorder creates a ReportRecorder. baseURL is the URL of the Grafana
+// instance used to link resources whose handler knows their URL. Links are
+// omitted if it is empty.
+func NewReportRecorder(wr EventsRecorder, out io.Writer, title string, registry Registry, baseURL string) *ReportRecorder {
+	return &ReportRecorder{
+		wr:       wr,
+		out:      out,
+		title:    title,
+		registry: registry,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		events:   make(map[string][]Event),
+		summary: &Summary{
+			EventCounts: make(map[EventType]int),
+		},
+	}
+}
+
+// Record implements EventsRecorder.
+func (recorder *ReportRecorder) Record(event Event) {
+	recorder.wr.Record(event)
+	recorder.summary.EventCounts[event.Type] += 1
+
+	kind, _, _ := strings.Cut(event.ResourceRef, ".")
+	if _, seen := recorder.events[kind]; !seen {
+		recorder.kinds = append(recorder.kinds, kind)
+	}
+	recorder.events[kind] = append(recorder.events[kind], event)
+}
+
+// Summary implements EventsRecorder.
+func (recorder *ReportRecorder) Summary() Summary {
+	return recorder.wr.Summary()
+}
+
+// Flush implements EventsFlusher.
+func (recorder *ReportRecorder) Flush() error {
+	var report strings.Builder
+
+	fmt.Fprintf(&report, "# %s\n\n", recorder.title)
+
+	if len(recorder.kinds) == 0 {
+		report.WriteString("No resources.\n")
+		_, err := io.WriteString(recorder.out, report.String())
+		return err
+	}
+
+	report.WriteString("| Status | Resources |\n| --- | --- |\n")
+	for _, eventType := range recorder.sortedEventTypes() {
+		fmt.Fprintf(&report, "| %s | %d |\n", eventType.HumanReadable, recorder.summary.EventCounts[eventType])
+	}
+
+	for _, kind := range recorder.kinds {
+		fmt.Fprintf(&report, "\n## %s\n\n", kind)
+
+		report.WriteString("| Resource | Status | File |\n| --- | --- | --- |\n")
+		for _, event := range recorder.events[kind] {
+			fmt.Fprintf(&report, "| %s | %s | %s |\n", recorder.resourceCell(event), recorder.statusCell(event), markdownCode(event.ResourcePath))
+		}
+
+		for _, event := range recorder.events[kind] {
+			if event.Diff == "" {
+				continue
+			}
+
+			fence := "```"
+			for strings.Contains(event.Diff, fence) {
+				fence += "`"
+			}
+
+			fmt.Fprintf(&report, "\n<details>\n<summary>%s</summary>\n\n%sdiff\n%s", html.EscapeString(event.ResourceRef), fence, event.Diff)
+			if !strings.HasSuffix(event.Diff, "\n") {
+				report.WriteString("\n")
+			}
+			fmt.Fprintf(&report, "%s\n\n</details>\n", fence)
+		}
+	}
+
+	_, err := io.WriteString(recorder.out, report.String())
+	return err
+}
+
+// sortedEventTypes lists the recorded event types, most severe first.
+func (recorder *ReportRecorder) sortedEventTypes() []EventType {
+	eventTypes := make([]EventType, 0, len(recorder.summary.EventCounts))
+	for eventType, count := range recorder.summary.EventCounts {
+		if count == 0 {
+			continue
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	sort.Slice(eventTypes, func(i, j int) bool {
+		if eventTypes[i].Severity != eventTypes[j].Severity {
+			return eventTypes[i].Severity > eventTypes[j].Severity
+		}
+		return eventTypes[i].HumanReadable < eventTypes[j].HumanReadable
+	})
+
+	return eventTypes
+}
+
+func (recorder *ReportRecorder) resourceCell(event Event) string {
+	ref := escapeMarkdownTable(event.ResourceRef)
+
+	url := recorder.resourceURL(event.ResourceRef)
+	if url == "" {
+		return ref
+	}
+
+	return fmt.Sprintf("[%s](%s)", ref, url)
+}
+
+func (recorder *ReportRecorder) statusCell(event Event) string {
+	if event.Details == "" {
+		return event.Type.HumanReadable
+	}
+
+	return escapeMarkdownTable(fmt.Sprintf("%s: %s", event.Type.HumanReadable, event.Details))
+}
+
+// resourceURL returns the URL of a resource in Grafana, if its handler knows
+// how to build it.
+func (recorder *ReportRecorder) resourceURL(ref string) string {
+	if recorder.baseURL == "" {
+		return ""
+	}
+
+	kind, name, found := strings.Cut(ref, ".")
+	if !found {
+		return ""
+	}
+
+	handler, err := recorder.registry.GetHandler(kind)
+	if err != nil {
+		return ""
+	}
+
+	proxyConfigProvider, ok := handler.(ProxyConfiguratorProvider)
+	if !ok {
+		return ""
+	}
+
+	path := proxyConfigProvider.ProxyConfigurator().ProxyURL(name)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return recorder.baseURL + path
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + escapeMarkdownTable(strings.ReplaceAll(s, "`", "'")) + "`"
+}
+
+func escapeMarkdownTable(s string) string {
+	return strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ").Replace(s)
+}
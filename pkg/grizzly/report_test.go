@@ -0,0 +1,77 @@
+package grizzly_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+// linkedHandler is a fakeHandler knowing the URL of its resources.
+type linkedHandler struct {
+	*fakeHandler
+}
+
+func (h *linkedHandler) ProxyConfigurator() grizzly.ProxyConfigurator {
+	return linkedProxyConfigurator{}
+}
+
+type linkedProxyConfigurator struct{}
+
+func (linkedProxyConfigurator) Endpoints(s grizzly.Server) []grizzly.HTTPEndpoint { return nil }
+func (linkedProxyConfigurator) StaticEndpoints() grizzly.StaticProxyConfig {
+	return grizzly.StaticProxyConfig{}
+}
+func (linkedProxyConfigurator) ProxyURL(uid string) string {
+	return fmt.Sprintf("/widgets/%s", uid)
+}
+
+func TestReportRecorder(t *testing.T) {
+	provider := &fakeProvider{}
+	provider.handlers = []grizzly.Handler{
+		&linkedHandler{newFakeHandler(provider, "Widget", map[string]map[string]any{
+			"changed": {"title": "remote"},
+			"same":    {"title": "same"},
+		})},
+		newFakeHandler(provider, "Gadget", map[string]map[string]any{}),
+	}
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	newResource := func(kind, name, title string) grizzly.Resource {
+		resource, err := grizzly.NewResource(provider.APIVersion(), kind, name, map[string]any{"title": title})
+		require.NoError(t, err)
+		resource.SetSource(grizzly.Source{Path: kind + "/" + name + ".yaml"})
+		return resource
+	}
+	resources := grizzly.NewResources(
+		newResource("Widget", "changed", "local"),
+		newResource("Widget", "same", "same"),
+		newResource("Gadget", "missing", "missing"),
+	)
+
+	var out bytes.Buffer
+	wrapped := &memoryRecorder{}
+	recorder := grizzly.NewReportRecorder(wrapped, &out, "grr diff", registry, "https://grafana.example.com/")
+
+	require.NoError(t, grizzly.Diff(registry, resources, false, "yaml", recorder))
+	require.NoError(t, recorder.Flush())
+
+	require.Len(t, wrapped.events, 3)
+	require.Equal(t, grizzly.ResourceChanged, wrapped.events[0].Type)
+	require.Contains(t, wrapped.events[0].Diff, "-    title: remote")
+	require.Contains(t, wrapped.events[0].Diff, "+    title: local")
+
+	report := out.String()
+	require.Contains(t, report, "# grr diff\n")
+	require.Contains(t, report, "| changed | 1 |\n")
+	require.Contains(t, report, "| not found | 1 |\n")
+	require.Contains(t, report, "| unchanged | 1 |\n")
+	require.Contains(t, report, "\n## Widget\n")
+	require.Contains(t, report, "\n## Gadget\n")
+	require.Contains(t, report, "| [Widget.changed](https://grafana.example.com/widgets/changed) | changed | `Widget/changed.yaml` |\n")
+	require.Contains(t, report, "| Gadget.missing | not found | `Gadget/missing.yaml` |\n")
+	require.Contains(t, report, "<details>\n<summary>Widget.changed</summary>\n\n```diff\n")
+	require.NotContains(t, report, "<summary>Widget.same</summary>")
+}
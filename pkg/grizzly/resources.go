@@ -40,6 +40,9 @@ type Source struct {
 	Rewritable bool
 	// WithEnvelope indicates whether the resource had an envelope or not.
 	WithEnvelope bool
+	// Encrypted indicates whether the file describing the resource is
+	// encrypted with SOPS.
+	Encrypted bool
 }
 
 // Resource represents a single Resource destined for a single endpoint
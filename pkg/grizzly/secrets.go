@@ -0,0 +1,218 @@
+package grizzly
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SecretKey is the key of secret references. A secret reference is a map
+// holding this single key, which describes where the secret comes from:
+//
+//	password:
+//	  $secret: {env: DB_PASSWORD}
+//	url:
+//	  $secret: {file: secrets/slack-webhook}
+//	token:
+//	  $secret: {command: [vault, read, -field=token, secret/grafana]}
+//
+// References are only resolved right before resources are sent to a remote
+// endpoint: everywhere else, resources hold the references themselves.
+const SecretKey = "$secret"
+
+// RedactedSecret replaces secret references when resources are displayed. It
+// matches the placeholder Grafana returns for secure settings.
+const RedactedSecret = "[REDACTED]"
+
+// HasSecrets tells whether the resource holds secret references.
+func HasSecrets(resource Resource) bool {
+	return containsSecrets(resource.Body)
+}
+
+// RedactSecrets returns a copy of the resource in which secret references are
+// replaced by RedactedSecret.
+func RedactSecrets(resource Resource) Resource {
+	body, _ := mapSecrets(resource.Body, func(map[string]any) (any, error) {
+		return RedactedSecret, nil
+	})
+	resource.Body = body.(map[string]any)
+	return resource
+}
+
+// ResolveSecrets returns a copy of the resource in which secret references are
+// replaced by the secrets they designate. Relative file paths are resolved
+// from the directory of the file the resource was read from.
+func ResolveSecrets(resource Resource) (Resource, error) {
+	baseDir := ""
+	if resource.Source.Path != "" {
+		baseDir = filepath.Dir(resource.Source.Path)
+	}
+
+	body, err := mapSecrets(resource.Body, func(reference map[string]any) (any, error) {
+		return resolveSecret(reference, baseDir)
+	})
+	if err != nil {
+		return resource, fmt.Errorf("resolving secrets of %s: %w", resource.Ref(), err)
+	}
+
+	resource.Body = body.(map[string]any)
+	return resource, nil
+}
+
+// preserveSecretReferences copies the secret references of local into a copy
+// of remote, at the same locations. This keeps references in local files when
+// they are overwritten with remote resources, which hold no or redacted
+// secrets.
+func preserveSecretReferences(local Resource, remote Resource) Resource {
+	remote.Body = copySecretReferences(local.Body, remote.Body).(map[string]any)
+	return remote
+}
+
+func copySecretReferences(local any, remote any) any {
+	localMap, ok := local.(map[string]any)
+	if !ok {
+		return remote
+	}
+	if isSecretReference(localMap) {
+		return localMap
+	}
+
+	remoteMap, ok := remote.(map[string]any)
+	if !ok && remote != nil {
+		return remote
+	}
+
+	var result map[string]any
+	for key, value := range localMap {
+		if !containsSecrets(value) {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]any, len(remoteMap))
+			for k, v := range remoteMap {
+				result[k] = v
+			}
+		}
+		result[key] = copySecretReferences(value, remoteMap[key])
+	}
+	if result == nil {
+		return remote
+	}
+
+	return result
+}
+
+func containsSecrets(value any) bool {
+	found := false
+	_, _ = mapSecrets(value, func(map[string]any) (any, error) {
+		found = true
+		return nil, nil
+	})
+	return found
+}
+
+func isSecretReference(m map[string]any) bool {
+	_, ok := m[SecretKey]
+	return ok && len(m) == 1
+}
+
+// mapSecrets returns a copy of value in which secret references are replaced
+// by the result of fn.
+func mapSecrets(value any, fn func(reference map[string]any) (any, error)) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if isSecretReference(v) {
+			return fn(v)
+		}
+
+		var errs []error
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			mapped, err := mapSecrets(item, fn)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+			result[key] = mapped
+		}
+		return result, errors.Join(errs...)
+
+	case []any:
+		var errs []error
+		result := make([]any, len(v))
+		for i, item := range v {
+			mapped, err := mapSecrets(item, fn)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("[%d]: %w", i, err))
+			}
+			result[i] = mapped
+		}
+		return result, errors.Join(errs...)
+	}
+
+	return value, nil
+}
+
+func resolveSecret(reference map[string]any, baseDir string) (any, error) {
+	source, ok := reference[SecretKey].(map[string]any)
+	if !ok || len(source) != 1 {
+		return nil, fmt.Errorf("%s must hold exactly one of env, file or command", SecretKey)
+	}
+
+	var value string
+	switch {
+	case source["env"] != nil:
+		name, ok := source["env"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.env must be a string", SecretKey)
+		}
+		if value, ok = os.LookupEnv(name); !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+
+	case source["file"] != nil:
+		path, ok := source["file"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s.file must be a string", SecretKey)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading secret file: %w", err)
+		}
+		value = strings.TrimRight(string(content), "\r\n")
+
+	case source["command"] != nil:
+		items, ok := source["command"].([]any)
+		if !ok || len(items) == 0 {
+			return nil, fmt.Errorf("%s.command must be a non-empty list of strings", SecretKey)
+		}
+		command := make([]string, 0, len(items))
+		for _, item := range items {
+			arg, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s.command must be a non-empty list of strings", SecretKey)
+			}
+			command = append(command, arg)
+		}
+
+		var stdout bytes.Buffer
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("running secret command: %w", err)
+		}
+		value = strings.TrimRight(stdout.String(), "\r\n")
+
+	default:
+		return nil, fmt.Errorf("%s must hold exactly one of env, file or command", SecretKey)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,158 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+// applyingHandler records the resources sent to the remote endpoint.
+type applyingHandler struct {
+	*fakeHandler
+	sent []grizzly.Resource
+}
+
+func (h *applyingHandler) Add(resource grizzly.Resource) error {
+	h.sent = append(h.sent, resource)
+	return nil
+}
+
+func (h *applyingHandler) Update(existing, resource grizzly.Resource) error {
+	h.sent = append(h.sent, resource)
+	return nil
+}
+
+func secretResource(t *testing.T, name string, settings map[string]any) grizzly.Resource {
+	t.Helper()
+	resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", "Widget", name, map[string]any{
+		"title":    name,
+		"settings": settings,
+	})
+	require.NoError(t, err)
+	return resource
+}
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("GRIZZLY_TEST_PASSWORD", "from-env")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "webhook"), []byte("from-file\n"), 0600))
+
+	resource := secretResource(t, "secrets", map[string]any{
+		"password": map[string]any{grizzly.SecretKey: map[string]any{"env": "GRIZZLY_TEST_PASSWORD"}},
+		"url":      map[string]any{grizzly.SecretKey: map[string]any{"file": "webhook"}},
+		"tokens": []any{
+			map[string]any{grizzly.SecretKey: map[string]any{"command": []any{"echo", "from-command"}}},
+		},
+		"user": "admin",
+	})
+	resource.Source.Path = filepath.Join(dir, "resource.yaml")
+
+	t.Run("references are resolved", func(t *testing.T) {
+		require.True(t, grizzly.HasSecrets(resource))
+
+		resolved, err := grizzly.ResolveSecrets(resource)
+		require.NoError(t, err)
+		require.False(t, grizzly.HasSecrets(resolved))
+		require.Equal(t, map[string]any{
+			"password": "from-env",
+			"url":      "from-file",
+			"tokens":   []any{"from-command"},
+			"user":     "admin",
+		}, resolved.GetSpecValue("settings"))
+
+		// the original resource still holds references
+		require.True(t, grizzly.HasSecrets(resource))
+	})
+
+	t.Run("references are redacted", func(t *testing.T) {
+		redacted := grizzly.RedactSecrets(resource)
+		require.Equal(t, map[string]any{
+			"password": grizzly.RedactedSecret,
+			"url":      grizzly.RedactedSecret,
+			"tokens":   []any{grizzly.RedactedSecret},
+			"user":     "admin",
+		}, redacted.GetSpecValue("settings"))
+	})
+
+	t.Run("invalid references are reported", func(t *testing.T) {
+		invalid := secretResource(t, "invalid", map[string]any{
+			"unset":   map[string]any{grizzly.SecretKey: map[string]any{"env": "GRIZZLY_TEST_UNSET"}},
+			"unknown": map[string]any{grizzly.SecretKey: map[string]any{"vault": "path"}},
+		})
+
+		_, err := grizzly.ResolveSecrets(invalid)
+		require.ErrorContains(t, err, "environment variable GRIZZLY_TEST_UNSET is not set")
+		require.ErrorContains(t, err, "$secret must hold exactly one of env, file or command")
+	})
+}
+
+func TestApplyResolvesSecrets(t *testing.T) {
+	t.Setenv("GRIZZLY_TEST_PASSWORD", "from-env")
+
+	provider := &fakeProvider{}
+	handler := &applyingHandler{fakeHandler: newFakeHandler(provider, "Widget", map[string]map[string]any{
+		"existing": {"title": "existing", "settings": map[string]any{"password": grizzly.RedactedSecret}},
+	})}
+	provider.handlers = []grizzly.Handler{handler}
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	reference := map[string]any{grizzly.SecretKey: map[string]any{"env": "GRIZZLY_TEST_PASSWORD"}}
+	resources := grizzly.NewResources(
+		secretResource(t, "new", map[string]any{"password": reference}),
+		secretResource(t, "existing", map[string]any{"password": reference}),
+	)
+
+	recorder := &memoryRecorder{}
+	require.NoError(t, grizzly.Apply(registry, resources, false, recorder))
+
+	require.Len(t, handler.sent, 2)
+	for _, sent := range handler.sent {
+		require.Equal(t, map[string]any{"password": "from-env"}, sent.GetSpecValue("settings"))
+	}
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceAdded])
+	require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceUpdated])
+	for _, event := range recorder.events {
+		require.NotContains(t, event.Diff, "from-env")
+	}
+}
+
+func TestPullPreservesSecretReferences(t *testing.T) {
+	provider := &fakeProvider{}
+	provider.handlers = []grizzly.Handler{
+		newFakeHandler(provider, "Widget", map[string]map[string]any{
+			"hook": {"title": "renamed", "settings": map[string]any{"url": grizzly.RedactedSecret}},
+		}),
+	}
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Widget", "hook.yaml")
+	require.NoError(t, grizzly.WriteFile(file, []byte(`apiVersion: grizzly.grafana.com/v1alpha1
+kind: Widget
+metadata:
+  name: hook
+spec:
+  title: hook
+  settings:
+    url:
+      $secret:
+        env: SLACK_WEBHOOK
+`)))
+
+	err := grizzly.Pull(registry, dir, grizzly.PullOptions{
+		OutputFormat: "yaml",
+		Parser:       grizzly.DefaultParser(registry, nil, nil, grizzly.ParserContinueOnError(true)),
+	}, &memoryRecorder{})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "title: renamed")
+	require.Contains(t, string(content), "$secret:")
+	require.Contains(t, string(content), "env: SLACK_WEBHOOK")
+	require.NotContains(t, string(content), grizzly.RedactedSecret)
+}
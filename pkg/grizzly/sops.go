@@ -0,0 +1,189 @@
+package grizzly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// sopsBinary is the command used to encrypt and decrypt SOPS files.
+const sopsBinary = "sops"
+
+// SOPSParser parses YAML and JSON files encrypted with SOPS. Files are
+// recognised by their name (*.enc.yaml, *.enc.yml, *.enc.json) or by the SOPS
+// metadata they hold, and decrypted with the sops command.
+type SOPSParser struct {
+	registry   Registry
+	ageKeyFile string
+	logger     *log.Entry
+}
+
+// NewSOPSParser returns a parser for SOPS-encrypted files. If ageKeyFile is
+// not empty, age keys are read from this file instead of the default
+// location used by sops.
+func NewSOPSParser(registry Registry, ageKeyFile string) *SOPSParser {
+	return &SOPSParser{
+		registry:   registry,
+		ageKeyFile: ageKeyFile,
+		logger:     log.WithField("parser", "sops"),
+	}
+}
+
+func (parser *SOPSParser) Accept(file string) bool {
+	format := sopsFileFormat(file)
+	if format == "" {
+		return false
+	}
+	if isEncryptedFilename(file) {
+		return true
+	}
+
+	return hasSOPSMetadata(file)
+}
+
+// Parse decrypts a SOPS file and parses it into resources
+func (parser *SOPSParser) Parse(file string, options ParserOptions) (Resources, error) {
+	parser.logger.WithField("file", file).Debug("Parsing file")
+
+	format := sopsFileFormat(file)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(sopsBinary, "--decrypt", "--input-type", format, "--output-type", format, file)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if parser.ageKeyFile != "" {
+		cmd.Env = append(os.Environ(), "SOPS_AGE_KEY_FILE="+parser.ageKeyFile)
+	}
+	if err := cmd.Run(); err != nil {
+		return Resources{}, fmt.Errorf("decrypting with sops: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	source := Source{
+		Format:     format,
+		Path:       file,
+		Rewritable: true,
+		Encrypted:  true,
+	}
+
+	resources := NewResources()
+	if format == formatJSON {
+		var m any
+		if err := json.Unmarshal(stdout.Bytes(), &m); err != nil {
+			return Resources{}, err
+		}
+		return parseAny(parser.registry, m, options.DefaultResourceKind, options.DefaultFolderUID, source)
+	}
+
+	decoder := yaml.NewDecoder(&stdout)
+	for {
+		var m any
+		err := decoder.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Resources{}, err
+		}
+
+		parsedResources, err := parseAny(parser.registry, m, options.DefaultResourceKind, options.DefaultFolderUID, source)
+		if err != nil {
+			return Resources{}, err
+		}
+		resources.Merge(parsedResources)
+	}
+
+	return resources, nil
+}
+
+// sopsEditor is the editor sops runs to update encrypted files: it writes its
+// standard input to the decrypted file, and fails when there is nothing to
+// write, so that sops stops rather than reopening the editor.
+const sopsEditor = `sh -c 'cat > "$1" && test -s "$1"' grizzly`
+
+// sopsFileNotModified is the exit code of sops when an edited file is unchanged.
+const sopsFileNotModified = 200
+
+// UpdateSOPSFile replaces the content of a SOPS-encrypted file. The file is
+// edited with sops, which re-encrypts it with the keys and rules of its own
+// metadata. The plaintext is handed to sops on its standard input.
+func UpdateSOPSFile(file string, content []byte, ageKeyFile string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(sopsBinary, file)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "SOPS_EDITOR="+sopsEditor, "EDITOR="+sopsEditor)
+	if ageKeyFile != "" {
+		cmd.Env = append(cmd.Env, "SOPS_AGE_KEY_FILE="+ageKeyFile)
+	}
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == sopsFileNotModified {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("encrypting with sops: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// encryptedFilename returns the name given to the encrypted version of file,
+// e.g. dashboard.enc.yaml for dashboard.yaml.
+func encryptedFilename(file string) string {
+	extension := filepath.Ext(file)
+	return strings.TrimSuffix(file, extension) + ".enc" + extension
+}
+
+// isEncryptedSOPSFile tells whether file exists and is encrypted with SOPS,
+// judging by its name or the SOPS metadata it holds.
+func isEncryptedSOPSFile(file string) bool {
+	if _, err := os.Stat(file); err != nil {
+		return false
+	}
+	if isEncryptedFilename(file) {
+		return true
+	}
+	return hasSOPSMetadata(file)
+}
+
+func isEncryptedFilename(file string) bool {
+	return filepath.Ext(strings.TrimSuffix(file, filepath.Ext(file))) == ".enc"
+}
+
+func sopsFileFormat(file string) string {
+	switch filepath.Ext(file) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".json":
+		return formatJSON
+	}
+	return ""
+}
+
+// hasSOPSMetadata tells whether file holds SOPS metadata.
+func hasSOPSMetadata(file string) bool {
+	content, err := os.ReadFile(file)
+	if err != nil || !bytes.Contains(content, []byte("sops")) {
+		return false
+	}
+
+	// JSON being valid YAML, both formats can be decoded alike.
+	var document struct {
+		SOPS *struct {
+			MAC string `yaml:"mac"`
+		} `yaml:"sops"`
+	}
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&document); err != nil {
+		return false
+	}
+	return document.SOPS != nil && document.SOPS.MAC != ""
+}
@@ -0,0 +1,178 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSOPS "decrypts" files by removing their SOPS metadata, and edits them
+// like sops does: the editor updates the decrypted file, which is "encrypted"
+// again with the metadata of the original file. Both fail if the age key file
+// isn't set.
+const fakeSOPS = `#!/bin/sh
+for arg; do file="$arg"; done
+[ -n "$SOPS_AGE_KEY_FILE" ] || { echo "no age key" >&2; exit 1; }
+case "$1" in
+--decrypt)
+	sed '/^sops:/,$d' "$file"
+	;;
+*)
+	dir=$(mktemp -d)
+	sed '/^sops:/,$d' "$file" > "$dir/plain"
+	cp "$dir/plain" "$dir/before"
+	eval "$SOPS_EDITOR \"\$dir/plain\"" || exit 1
+	cmp -s "$dir/plain" "$dir/before" && exit 200
+	{ cat "$dir/plain"; sed -n '/^sops:/,$p' "$file"; } > "$dir/encrypted"
+	mv "$dir/encrypted" "$file"
+	;;
+esac
+`
+
+func TestSOPSParser(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake sops command is a shell script")
+	}
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "sops"), []byte(fakeSOPS), 0700))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	provider := &fakeProvider{}
+	provider.handlers = []grizzly.Handler{
+		newFakeHandler(provider, "Widget", map[string]map[string]any{
+			"secret": {"title": "from-remote"},
+		}),
+	}
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+	parser := grizzly.DefaultParser(registry, nil, nil, grizzly.ParserSOPSAgeKeyFile("keys.txt"))
+
+	encrypted := `apiVersion: grizzly.grafana.com/v1alpha1
+kind: Widget
+metadata:
+  name: secret
+spec:
+  title: from-file
+sops:
+  mac: fake
+  encrypted_regex: ^spec$
+  age:
+    - recipient: age1recipient
+  pgp:
+    - fp: 85D77543B3D624B63CEA9E6DBC17301B491B3F21
+`
+
+	t.Run("encrypted files are recognised by name or metadata", func(t *testing.T) {
+		dir := t.TempDir()
+		named := filepath.Join(dir, "named.enc.yaml")
+		require.NoError(t, os.WriteFile(named, []byte(encrypted), 0600))
+		withMetadata := filepath.Join(dir, "metadata.yaml")
+		require.NoError(t, os.WriteFile(withMetadata, []byte(encrypted), 0600))
+		plain := filepath.Join(dir, "plain.yaml")
+		require.NoError(t, os.WriteFile(plain, []byte("kind: Widget\n"), 0600))
+
+		sops := grizzly.NewSOPSParser(registry, "")
+		require.True(t, sops.Accept(named))
+		require.True(t, sops.Accept(withMetadata))
+		require.False(t, sops.Accept(plain))
+	})
+
+	t.Run("encrypted files are decrypted", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "secret.enc.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(encrypted), 0600))
+
+		resources, err := parser.Parse(file, grizzly.ParserOptions{})
+		require.NoError(t, err)
+		require.Equal(t, 1, resources.Len())
+
+		resource := resources.First()
+		require.Equal(t, "from-file", resource.GetSpecValue("title"))
+		require.True(t, resource.Source.Encrypted)
+		require.False(t, resource.HasSpecString("sops"))
+	})
+
+	t.Run("decryption failures are reported", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "secret.enc.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(encrypted), 0600))
+
+		_, err := grizzly.DefaultParser(registry, nil, nil).Parse(file, grizzly.ParserOptions{})
+		require.ErrorContains(t, err, "no age key")
+	})
+
+	t.Run("pulled resources are re-encrypted with the metadata of the file", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "Widget", "secret.enc.yaml")
+		require.NoError(t, grizzly.WriteFile(file, []byte(encrypted)))
+
+		err := grizzly.Pull(registry, dir, grizzly.PullOptions{
+			OutputFormat:   "yaml",
+			Parser:         parser,
+			SOPSAgeKeyFile: "keys.txt",
+		}, &memoryRecorder{})
+		require.NoError(t, err)
+
+		require.NoFileExists(t, filepath.Join(dir, "Widget", "secret.yaml"))
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "title: from-remote")
+		require.Contains(t, string(content), "encrypted_regex: ^spec$")
+		require.Contains(t, string(content), "recipient: age1recipient")
+		require.Contains(t, string(content), "fp: 85D77543B3D624B63CEA9E6DBC17301B491B3F21")
+	})
+
+	t.Run("unchanged encrypted files are left as is", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "secret.enc.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(encrypted), 0600))
+
+		plain := "apiVersion: grizzly.grafana.com/v1alpha1\nkind: Widget\nmetadata:\n  name: secret\nspec:\n  title: from-file\n"
+		require.NoError(t, grizzly.UpdateSOPSFile(file, []byte(plain), "keys.txt"))
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, encrypted, string(content))
+	})
+
+	t.Run("encrypted files that can't be decrypted aren't overwritten", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "Widget", "secret.enc.yaml")
+		require.NoError(t, grizzly.WriteFile(file, []byte(encrypted)))
+
+		recorder := &memoryRecorder{}
+		err := grizzly.Pull(registry, dir, grizzly.PullOptions{
+			OutputFormat: "yaml",
+			Parser:       grizzly.DefaultParser(registry, nil, nil),
+		}, recorder)
+		require.ErrorContains(t, err, "no age key")
+		require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceFailure])
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, encrypted, string(content))
+		require.NoFileExists(t, filepath.Join(dir, "Widget", "secret.yaml"))
+	})
+
+	t.Run("encrypted files are recognised by metadata when pulling", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "Widget", "secret.yaml")
+		require.NoError(t, grizzly.WriteFile(file, []byte(encrypted)))
+
+		// without sops, the file can't be re-encrypted
+		t.Setenv("PATH", t.TempDir())
+
+		recorder := &memoryRecorder{}
+		err := grizzly.Pull(registry, dir, grizzly.PullOptions{
+			OutputFormat: "yaml",
+			Parser:       parser,
+		}, recorder)
+		require.Error(t, err)
+		require.Equal(t, 1, recorder.Summary().EventCounts[grizzly.ResourceFailure])
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, encrypted, string(content))
+	})
+}
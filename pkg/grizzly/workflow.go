@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"text/tabwriter"
 
@@ -177,10 +180,36 @@ func listWide(listedResources []listedResource) ([]byte, error) {
 	return out.Bytes(), err
 }
 
+// PullOptions holds the settings used to pull remote resources.
+type PullOptions struct {
+	OnlySpec        bool
+	OutputFormat    string
+	Targets         []string
+	ContinueOnError bool
+	// Layout describes how pulled resources are organised on disk.
+	// See LayoutDefault and LayoutFolders.
+	Layout string
+
+	// Sync removes local files describing resources that don't exist
+	// remotely anymore. Parser and ParserOptions are used to read them: the
+	// parser is expected not to filter resources by target. They are also
+	// used to keep the secret references of overwritten local resources.
+	Sync          bool
+	Parser        Parser
+	ParserOptions ParserOptions
+
+	// SOPSAgeKeyFile is the file holding the age keys used to update
+	// SOPS-encrypted files. Defaults to the location used by sops.
+	SOPSAgeKeyFile string
+}
+
 // Pull pulls remote resources and stores them in the local file system.
 // The given resourcePath must be a directory, where all resources will be stored.
-// If opts.JSONSpec is true, which is only applicable for dashboards, saves the spec as a JSON file.
-func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat string, targets []string, continueOnError bool, eventsRecorder EventsRecorder) error {
+// If opts.OnlySpec is true, which is only applicable for dashboards, saves the spec as a JSON file.
+func Pull(registry Registry, resourcePath string, opts PullOptions, eventsRecorder EventsRecorder) error {
+	targets := opts.Targets
+	continueOnError := opts.ContinueOnError
+
 	resourcePathIsFile, err := isFile(resourcePath)
 	if err != nil {
 		return err
@@ -190,7 +219,19 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 		return fmt.Errorf("pull <resource-path> must be a directory")
 	}
 
+	switch opts.Layout {
+	case "", LayoutDefault, LayoutFolders:
+	default:
+		return fmt.Errorf("unknown layout '%s', expected one of %s, %s", opts.Layout, LayoutDefault, LayoutFolders)
+	}
+
+	if opts.Sync && opts.Parser == nil {
+		return fmt.Errorf("a parser is required to sync local resources")
+	}
+
 	var finalErr error
+	// remote UIDs listed for each pulled kind, used to detect stale local files
+	remoteUIDs := map[string]map[string]struct{}{}
 
 	log.Infof("Pulling resources to %s", resourcePath)
 	for name, handler := range registry.Handlers {
@@ -215,6 +256,12 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 
 			return finalErr
 		}
+
+		remoteUIDs[handler.Kind()] = make(map[string]struct{}, len(UIDs))
+		for _, UID := range UIDs {
+			remoteUIDs[handler.Kind()][UID] = struct{}{}
+		}
+
 		if len(UIDs) == 0 {
 			notifier.Info(nil, "No resources found")
 			continue
@@ -253,7 +300,7 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 
 			resource = handler.Unprepare(*resource)
 
-			content, filename, _, err := Format(registry, resourcePath, resource, outputFormat, onlySpec)
+			content, _, extension, err := Format(registry, resourcePath, resource, opts.OutputFormat, opts.OnlySpec)
 			if err != nil {
 				finalErr = multierror.Append(finalErr, err)
 				eventsRecorder.Record(Event{
@@ -269,13 +316,40 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 				return finalErr
 			}
 
-			err = WriteFile(filename, content)
+			filename, err := getLayoutFilename(registry, resourcePath, resource, extension, opts.Layout)
 			if err != nil {
 				finalErr = multierror.Append(finalErr, err)
 				eventsRecorder.Record(Event{
 					Type:        ResourceFailure,
 					ResourceRef: resource.Ref().String(),
-					Details:     fmt.Sprintf("failed writing resource to file: %s", err),
+					Details:     fmt.Sprintf("failed computing resource path: %s", err),
+				})
+
+				if continueOnError {
+					continue
+				}
+
+				return finalErr
+			}
+
+			// resources read from encrypted files are written back to them
+			if _, err := os.Stat(filename); err != nil {
+				if _, err := os.Stat(encryptedFilename(filename)); err == nil {
+					filename = encryptedFilename(filename)
+				}
+			}
+
+			// the target file decides whether the content must be encrypted,
+			// whether or not the local resource could be read from it
+			encrypted := isEncryptedSOPSFile(filename)
+			localResource, found, err := findLocalResource(filename, *resource, opts)
+			if err != nil && encrypted {
+				finalErr = multierror.Append(finalErr, err)
+				eventsRecorder.Record(Event{
+					Type:         ResourceFailure,
+					ResourceRef:  resource.Ref().String(),
+					ResourcePath: filename,
+					Details:      fmt.Sprintf("failed reading encrypted file, left untouched: %s", err),
 				})
 
 				if continueOnError {
@@ -284,14 +358,206 @@ func Pull(registry Registry, resourcePath string, onlySpec bool, outputFormat st
 
 				return finalErr
 			}
+			if found && HasSecrets(localResource) {
+				// the remote resource holds no or redacted secrets: keep the
+				// references of the local one
+				pulled := preserveSecretReferences(localResource, *resource)
+				resource = &pulled
+				content, _, _, err = Format(registry, resourcePath, resource, opts.OutputFormat, opts.OnlySpec)
+				if err != nil {
+					finalErr = multierror.Append(finalErr, err)
+					eventsRecorder.Record(Event{
+						Type:        ResourceFailure,
+						ResourceRef: resource.Ref().String(),
+						Details:     fmt.Sprintf("failed formatting resource: %s", err),
+					})
+
+					if continueOnError {
+						continue
+					}
+
+					return finalErr
+				}
+			}
+
+			if encrypted {
+				if err := UpdateSOPSFile(filename, content, opts.SOPSAgeKeyFile); err != nil {
+					finalErr = multierror.Append(finalErr, err)
+					eventsRecorder.Record(Event{
+						Type:         ResourceFailure,
+						ResourceRef:  resource.Ref().String(),
+						ResourcePath: filename,
+						Details:      fmt.Sprintf("failed encrypting resource, file left untouched: %s", err),
+					})
+
+					if continueOnError {
+						continue
+					}
+
+					return finalErr
+				}
+			} else if err := WriteFile(filename, content); err != nil {
+				finalErr = multierror.Append(finalErr, err)
+				eventsRecorder.Record(Event{
+					Type:         ResourceFailure,
+					ResourceRef:  resource.Ref().String(),
+					ResourcePath: filename,
+					Details:      fmt.Sprintf("failed writing resource to file: %s", err),
+				})
+
+				if continueOnError {
+					continue
+				}
+
+				return finalErr
+			}
+
+			eventsRecorder.Record(Event{
+				Type:         ResourcePulled,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: filename,
+			})
+		}
+	}
+
+	if opts.Sync {
+		if err := removeStaleFiles(registry, resourcePath, opts, remoteUIDs, eventsRecorder); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+		}
+	}
+
+	return finalErr
+}
+
+// findLocalResource reads the local version of a pulled resource from the file
+// it is about to be written to, if any. An error is returned if the file
+// exists but can't be parsed.
+func findLocalResource(filename string, resource Resource, opts PullOptions) (Resource, bool, error) {
+	if opts.Parser == nil {
+		return Resource{}, false, nil
+	}
+	if _, err := os.Stat(filename); err != nil {
+		return Resource{}, false, nil
+	}
+
+	local, err := opts.Parser.Parse(filename, opts.ParserOptions)
+	if err != nil {
+		log.Debugf("Could not read local version of %s from %s: %s", resource.Ref(), filename, err)
+		return Resource{}, false, err
+	}
+
+	found, ok := local.Find(resource.Ref())
+	return found, ok, nil
+}
+
+// removeStaleFiles deletes local rewritable files that only describe resources
+// that don't exist remotely anymore. Only the targeted resources of the kinds
+// listed in remoteUIDs are considered: files describing anything else, or that
+// can't be parsed, are left untouched.
+func removeStaleFiles(registry Registry, resourcePath string, opts PullOptions, remoteUIDs map[string]map[string]struct{}, eventsRecorder EventsRecorder) error {
+	var files []string
+	err := filepath.WalkDir(resourcePath, func(path string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && opts.Parser.Accept(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var finalErr error
+	for _, file := range files {
+		resources, err := opts.Parser.Parse(file, opts.ParserOptions)
+		if err != nil {
+			if !IsWarning(err) {
+				log.Warnf("Local file won't be synced: %s", err)
+			}
+			continue
+		}
 
-			eventsRecorder.Record(Event{Type: ResourcePulled, ResourceRef: resource.Ref().String()})
+		stale := staleResources(registry, resources, opts.Targets, remoteUIDs)
+		if len(stale) == 0 {
+			continue
+		}
+		if len(stale) != resources.Len() {
+			log.Warnf("%s describes both existing and removed resources, leaving it untouched", file)
+			continue
+		}
+
+		if err := os.Remove(file); err != nil {
+			finalErr = multierror.Append(finalErr, err)
+			for _, resource := range stale {
+				eventsRecorder.Record(Event{
+					Type:         ResourceFailure,
+					ResourceRef:  resource.Ref().String(),
+					ResourcePath: file,
+					Details:      fmt.Sprintf("failed removing stale file: %s", err),
+				})
+			}
+			continue
+		}
+
+		for _, resource := range stale {
+			eventsRecorder.Record(Event{
+				Type:         ResourceRemoved,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: file,
+			})
 		}
 	}
 
 	return finalErr
 }
 
+// staleResources returns the rewritable resources that were targeted by a pull
+// but don't exist remotely. Resources selecting a scope are never stale.
+func staleResources(registry Registry, resources Resources, targets []string, remoteUIDs map[string]map[string]struct{}) []Resource {
+	var stale []Resource
+	for _, resource := range resources.AsList() {
+		if !resource.Source.Rewritable {
+			continue
+		}
+
+		// Only the scope of the context is pulled: resources of other
+		// scopes can't be told stale.
+		if _, _, scoped := registry.scopeOf(resource); scoped {
+			continue
+		}
+
+		uids, pulled := remoteUIDs[resource.Kind()]
+		if !pulled {
+			continue
+		}
+
+		handler, err := registry.GetHandler(resource.Kind())
+		if err != nil {
+			continue
+		}
+
+		uid := resource.Name()
+		if handlerUID, err := handler.GetUID(resource); err == nil {
+			uid = handlerUID
+		}
+
+		if !registry.ResourceMatchesTarget(resource.Kind(), uid, targets) {
+			continue
+		}
+
+		if _, exists := uids[uid]; !exists {
+			stale = append(stale, resource)
+		}
+	}
+
+	return stale
+}
+
 // Show displays resources
 func Show(registry Registry, resources Resources, outputFormat string) error {
 	log.Infof("Showing %d resources", resources.Len())
@@ -302,7 +568,7 @@ func Show(registry Registry, resources Resources, outputFormat string) error {
 		if err != nil {
 			return err
 		}
-		resource = *(handler.Unprepare(resource))
+		resource = RedactSecrets(*handler.Unprepare(resource))
 
 		content, _, _, err := Format(registry, "", &resource, outputFormat, false) // we always show full resource, even if only-spec was specified
 		if err != nil {
@@ -326,16 +592,16 @@ func Show(registry Registry, resources Resources, outputFormat string) error {
 }
 
 // Diff compares resources to those at the endpoints
-func Diff(registry Registry, resources Resources, onlySpec bool, outputFormat string) error {
+func Diff(registry Registry, resources Resources, onlySpec bool, outputFormat string, eventsRecorder EventsRecorder) error {
 	log.Infof("Diff-ing %d resources", resources.Len())
 
 	for _, resource := range resources.AsList() {
-		handler, err := registry.GetHandler(resource.Kind())
+		handler, err := registry.GetHandlerFor(resource)
 		if err != nil {
 			return err
 		}
 
-		resource = *handler.Unprepare(resource)
+		resource = RedactSecrets(*handler.Unprepare(resource))
 
 		local, _, _, err := Format(registry, "", &resource, outputFormat, onlySpec)
 		if err != nil {
@@ -348,13 +614,26 @@ func Diff(registry Registry, resources Resources, onlySpec bool, outputFormat st
 		remote, err := handler.GetRemote(resource)
 		if errors.Is(err, ErrNotFound) {
 			notifier.NotFound(resource)
+			eventsRecorder.Record(Event{
+				Type:         ResourceNotFound,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: resource.Source.Path,
+			})
 			continue
 		}
 
 		if err != nil {
-			return fmt.Errorf("Error retrieving resource from %s %s: %v", resource.Kind(), uid, err)
+			err = fmt.Errorf("Error retrieving resource from %s %s: %v", resource.Kind(), uid, err)
+			eventsRecorder.Record(Event{
+				Type:         ResourceFailure,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: resource.Source.Path,
+				Details:      err.Error(),
+			})
+			return err
 		}
 
+		registry.copyScope(resource, remote)
 		remote = handler.Unprepare(*remote)
 
 		remoteRepresentation, _, _, err := Format(registry, "", remote, outputFormat, onlySpec)
@@ -364,22 +643,39 @@ func Diff(registry Registry, resources Resources, onlySpec bool, outputFormat st
 
 		if string(local) == string(remoteRepresentation) {
 			notifier.NoChanges(resource)
+			eventsRecorder.Record(Event{
+				Type:         ResourceNotChanged,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: resource.Source.Path,
+			})
 		} else {
-			diff := difflib.UnifiedDiff{
-				A:        difflib.SplitLines(string(remoteRepresentation)),
-				B:        difflib.SplitLines(string(local)),
-				FromFile: "Remote",
-				ToFile:   "Local",
-				Context:  3,
-			}
-			difference, _ := difflib.GetUnifiedDiffString(diff)
+			difference := unifiedDiff(string(remoteRepresentation), string(local))
 			notifier.HasChanges(resource, difference)
+			eventsRecorder.Record(Event{
+				Type:         ResourceChanged,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: resource.Source.Path,
+				Diff:         difference,
+			})
 		}
 	}
 
 	return nil
 }
 
+func unifiedDiff(remote string, local string) string {
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(remote),
+		B:        difflib.SplitLines(local),
+		FromFile: "Remote",
+		ToFile:   "Local",
+		Context:  3,
+	}
+	difference, _ := difflib.GetUnifiedDiffString(diff)
+
+	return difference
+}
+
 type EventsRecorder interface {
 	Record(event Event)
 	Summary() Summary
@@ -395,9 +691,10 @@ func Apply(registry Registry, resources Resources, continueOnError bool, eventsR
 			finalErr = multierror.Append(finalErr, err)
 
 			eventsRecorder.Record(Event{
-				Type:        ResourceFailure,
-				ResourceRef: resource.Ref().String(),
-				Details:     err.Error(),
+				Type:         ResourceFailure,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: resource.Source.Path,
+				Details:      err.Error(),
 			})
 
 			if !continueOnError {
@@ -412,7 +709,7 @@ func Apply(registry Registry, resources Resources, continueOnError bool, eventsR
 func applyResource(registry Registry, resource Resource, trailRecorder EventsRecorder) error {
 	resourceRef := resource.Ref().String()
 
-	handler, err := registry.GetHandler(resource.Kind())
+	handler, err := registry.GetHandlerFor(resource)
 	if err != nil {
 		return err
 	}
@@ -422,14 +719,19 @@ func applyResource(registry Registry, resource Resource, trailRecorder EventsRec
 	if errors.Is(err, ErrNotFound) {
 		log.Debugf("`%s` was not found, adding it...", resource.Ref())
 
+		resource, err = ResolveSecrets(resource)
+		if err != nil {
+			return err
+		}
 		resource = *handler.Prepare(nil, resource)
 		if err := handler.Add(resource); err != nil {
 			return err
 		}
 
 		trailRecorder.Record(Event{
-			Type:        ResourceAdded,
-			ResourceRef: resourceRef,
+			Type:         ResourceAdded,
+			ResourceRef:  resourceRef,
+			ResourcePath: resource.Source.Path,
 		})
 		return nil
 	}
@@ -438,12 +740,21 @@ func applyResource(registry Registry, resource Resource, trailRecorder EventsRec
 	}
 
 	log.Debugf("`%s` was found, updating it...", resource.Ref())
+	registry.copyScope(resource, existingResource)
 
-	resourceRepresentation, err := resource.YAML()
+	// secrets can't be compared with their remote counterparts, which are
+	// either hidden or redacted: resources holding some are always updated.
+	hasSecrets := HasSecrets(resource)
+	redactedResource := RedactSecrets(resource)
+	resourceRepresentation, err := redactedResource.YAML()
 	if err != nil {
 		return err
 	}
 
+	resource, err = ResolveSecrets(resource)
+	if err != nil {
+		return err
+	}
 	resource = *handler.Prepare(existingResource, resource)
 	existingResource = handler.Unprepare(*existingResource)
 	existingResourceRepresentation, err := existingResource.YAML()
@@ -451,10 +762,11 @@ func applyResource(registry Registry, resource Resource, trailRecorder EventsRec
 		return err
 	}
 
-	if resourceRepresentation == existingResourceRepresentation {
+	if resourceRepresentation == existingResourceRepresentation && !hasSecrets {
 		trailRecorder.Record(Event{
-			Type:        ResourceNotChanged,
-			ResourceRef: resourceRef,
+			Type:         ResourceNotChanged,
+			ResourceRef:  resourceRef,
+			ResourcePath: resource.Source.Path,
 		})
 		return nil
 	}
@@ -464,17 +776,93 @@ func applyResource(registry Registry, resource Resource, trailRecorder EventsRec
 	}
 
 	trailRecorder.Record(Event{
-		Type:        ResourceUpdated,
-		ResourceRef: resourceRef,
+		Type:         ResourceUpdated,
+		ResourceRef:  resourceRef,
+		ResourcePath: resource.Source.Path,
+		Diff:         unifiedDiff(existingResourceRepresentation, resourceRepresentation),
 	})
 
 	return nil
 }
 
+// Delete deletes the remote equivalents of resources, if supported
+func Delete(registry Registry, resources Resources, continueOnError bool, eventsRecorder EventsRecorder) error {
+	var finalErr error
+
+	for _, resource := range DeletionOrder(registry, resources) {
+		err := deleteResource(registry, resource, eventsRecorder)
+		if err != nil {
+			finalErr = multierror.Append(finalErr, err)
+
+			eventsRecorder.Record(Event{
+				Type:         ResourceFailure,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: resource.Source.Path,
+				Details:      err.Error(),
+			})
+
+			if !continueOnError {
+				return finalErr
+			}
+		}
+	}
+
+	return finalErr
+}
+
+// DeletionOrder returns resources in the order they are deleted in: the
+// reverse of the order they are applied in, so that resources are deleted
+// before the ones holding them, e.g. dashboards before their folder.
+func DeletionOrder(registry Registry, resources Resources) []Resource {
+	sorted := registry.Sort(resources)
+	ordered := sorted.AsList()
+	slices.Reverse(ordered)
+
+	// resources with no handler are kept, to be reported as failures
+	for _, resource := range resources.AsList() {
+		if _, found := sorted.Find(resource.Ref()); !found {
+			ordered = append(ordered, resource)
+		}
+	}
+	return ordered
+}
+
+func deleteResource(registry Registry, resource Resource, eventsRecorder EventsRecorder) error {
+	handler, err := registry.GetHandlerFor(resource)
+	if err != nil {
+		return err
+	}
+	deleteHandler, ok := handler.(DeleteHandler)
+	if !ok {
+		return fmt.Errorf("%s resources can't be deleted", handler.Kind())
+	}
+
+	log.Debugf("Deleting `%s`", resource.Ref())
+	err = deleteHandler.Delete(resource)
+	if errors.Is(err, ErrNotFound) {
+		eventsRecorder.Record(Event{
+			Type:         ResourceNotFound,
+			ResourceRef:  resource.Ref().String(),
+			ResourcePath: resource.Source.Path,
+		})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	eventsRecorder.Record(Event{
+		Type:         ResourceRemoved,
+		ResourceRef:  resource.Ref().String(),
+		ResourcePath: resource.Source.Path,
+	})
+	return nil
+}
+
 // Snapshot pushes resources to endpoints as snapshots, if supported
 func Snapshot(registry Registry, resources Resources, expiresSeconds int) error {
 	for _, resource := range resources.AsList() {
-		handler, err := registry.GetHandler(resource.Kind())
+		handler, err := registry.GetHandlerFor(resource)
 		if err != nil {
 			return err
 		}
@@ -538,9 +926,10 @@ func Export(eventsRecorder EventsRecorder, registry Registry, exportDir string,
 			finalErr = multierror.Append(finalErr, err)
 
 			eventsRecorder.Record(Event{
-				Type:        ResourceFailure,
-				ResourceRef: resource.Ref().String(),
-				Details:     err.Error(),
+				Type:         ResourceFailure,
+				ResourceRef:  resource.Ref().String(),
+				ResourcePath: resource.Source.Path,
+				Details:      err.Error(),
 			})
 
 			if !continueOnError {
@@ -573,8 +962,9 @@ func exportResource(eventsRecorder EventsRecorder, registry Registry, exportDir
 
 	if string(existingResourceBytes) == string(updatedResourceBytes) {
 		eventsRecorder.Record(Event{
-			Type:        ResourceNotChanged,
-			ResourceRef: resource.Ref().String(),
+			Type:         ResourceNotChanged,
+			ResourceRef:  resource.Ref().String(),
+			ResourcePath: resource.Source.Path,
 		})
 		return nil
 	}
@@ -590,8 +980,9 @@ func exportResource(eventsRecorder EventsRecorder, registry Registry, exportDir
 	}
 
 	eventsRecorder.Record(Event{
-		Type:        eventType,
-		ResourceRef: resource.Ref().String(),
+		Type:         eventType,
+		ResourceRef:  resource.Ref().String(),
+		ResourcePath: resource.Source.Path,
 	})
 
 	return nil
@@ -0,0 +1,237 @@
+package grizzly_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	handlers []grizzly.Handler
+}
+
+func (p *fakeProvider) Name() string                   { return "Fake" }
+func (p *fakeProvider) Group() string                  { return "grizzly.grafana.com" }
+func (p *fakeProvider) Version() string                { return "v1alpha1" }
+func (p *fakeProvider) APIVersion() string             { return "grizzly.grafana.com/v1alpha1" }
+func (p *fakeProvider) GetHandlers() []grizzly.Handler { return p.handlers }
+func (p *fakeProvider) Validate() error                { return nil }
+func (p *fakeProvider) Status() grizzly.ProviderStatus { return grizzly.ProviderStatus{} }
+
+// scopedFakeProvider scopes resources with metadata.scope, every scope being
+// served by the provider itself.
+type scopedFakeProvider struct {
+	fakeProvider
+}
+
+func (p *scopedFakeProvider) ScopeMetadata() string { return "scope" }
+func (p *scopedFakeProvider) InScope(scope any) (grizzly.Provider, error) {
+	return p, nil
+}
+
+// fakeHandler serves resources from an in-memory map of UIDs to specs.
+type fakeHandler struct {
+	grizzly.BaseHandler
+	remote map[string]map[string]any
+}
+
+func newFakeHandler(provider grizzly.Provider, kind string, remote map[string]map[string]any) *fakeHandler {
+	return &fakeHandler{
+		BaseHandler: grizzly.NewBaseHandler(provider, kind, false),
+		remote:      remote,
+	}
+}
+
+func (h *fakeHandler) ResourceFilePath(resource grizzly.Resource, filetype string) string {
+	return filepath.Join(h.Kind(), resource.Name()+"."+filetype)
+}
+
+func (h *fakeHandler) GetSpecUID(resource grizzly.Resource) (string, error) {
+	return resource.Name(), nil
+}
+
+func (h *fakeHandler) GetByUID(uid string) (*grizzly.Resource, error) {
+	spec, ok := h.remote[uid]
+	if !ok {
+		return nil, grizzly.ErrNotFound
+	}
+	resource, err := grizzly.NewResource(h.APIVersion(), h.Kind(), uid, spec)
+	return &resource, err
+}
+
+func (h *fakeHandler) GetRemote(resource grizzly.Resource) (*grizzly.Resource, error) {
+	return h.GetByUID(resource.Name())
+}
+
+func (h *fakeHandler) ListRemote() ([]string, error) {
+	uids := make([]string, 0, len(h.remote))
+	for uid := range h.remote {
+		uids = append(uids, uid)
+	}
+	return uids, nil
+}
+
+func (h *fakeHandler) Add(resource grizzly.Resource) error              { return nil }
+func (h *fakeHandler) Update(existing, resource grizzly.Resource) error { return nil }
+func (h *fakeHandler) Validate(resource grizzly.Resource) error         { return nil }
+
+type memoryRecorder struct {
+	events []grizzly.Event
+}
+
+func (r *memoryRecorder) Record(event grizzly.Event) {
+	r.events = append(r.events, event)
+}
+
+func (r *memoryRecorder) Summary() grizzly.Summary {
+	summary := grizzly.Summary{EventCounts: map[grizzly.EventType]int{}}
+	for _, event := range r.events {
+		summary.EventCounts[event.Type]++
+	}
+	return summary
+}
+
+func TestPullSync(t *testing.T) {
+	provider := &fakeProvider{}
+	provider.handlers = []grizzly.Handler{
+		newFakeHandler(provider, "Widget", map[string]map[string]any{
+			"kept": {"title": "kept"},
+		}),
+		newFakeHandler(provider, "Gadget", map[string]map[string]any{}),
+	}
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	writeLocal := func(t *testing.T, dir string, path string, content string) string {
+		t.Helper()
+		fullPath := filepath.Join(dir, path)
+		require.NoError(t, grizzly.WriteFile(fullPath, []byte(content)))
+		return fullPath
+	}
+	envelope := func(kind, name string) string {
+		return "apiVersion: grizzly.grafana.com/v1alpha1\nkind: " + kind + "\nmetadata:\n  name: " + name + "\nspec:\n  title: " + name + "\n"
+	}
+	pull := func(t *testing.T, dir string, sync bool, targets []string) *memoryRecorder {
+		t.Helper()
+		recorder := &memoryRecorder{}
+		err := grizzly.Pull(registry, dir, grizzly.PullOptions{
+			OutputFormat: "yaml",
+			Targets:      targets,
+			Sync:         sync,
+			Parser:       grizzly.DefaultParser(registry, nil, nil, grizzly.ParserContinueOnError(true)),
+		}, recorder)
+		require.NoError(t, err)
+		return recorder
+	}
+
+	t.Run("stale files are kept without sync", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := writeLocal(t, dir, "Widget/stale.yaml", envelope("Widget", "stale"))
+
+		recorder := pull(t, dir, false, nil)
+
+		require.FileExists(t, stale)
+		require.FileExists(t, filepath.Join(dir, "Widget", "kept.yaml"))
+		require.Equal(t, 0, recorder.Summary().EventCounts[grizzly.ResourceRemoved])
+	})
+
+	t.Run("stale files are removed with sync", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := writeLocal(t, dir, "Widget/stale.yaml", envelope("Widget", "stale"))
+		staleGadget := writeLocal(t, dir, "elsewhere/gadget.yaml", envelope("Gadget", "old"))
+		notes := writeLocal(t, dir, "README.md", "not a resource")
+
+		recorder := pull(t, dir, true, nil)
+
+		require.NoFileExists(t, stale)
+		require.NoFileExists(t, staleGadget)
+		require.FileExists(t, notes)
+		require.FileExists(t, filepath.Join(dir, "Widget", "kept.yaml"))
+		require.Equal(t, 2, recorder.Summary().EventCounts[grizzly.ResourceRemoved])
+	})
+
+	t.Run("only targeted kinds are synced", func(t *testing.T) {
+		dir := t.TempDir()
+		stale := writeLocal(t, dir, "Widget/stale.yaml", envelope("Widget", "stale"))
+		staleGadget := writeLocal(t, dir, "Gadget/old.yaml", envelope("Gadget", "old"))
+
+		pull(t, dir, true, []string{"Gadget"})
+
+		require.FileExists(t, stale)
+		require.NoFileExists(t, staleGadget)
+	})
+
+	t.Run("files mixing existing and removed resources are kept", func(t *testing.T) {
+		dir := t.TempDir()
+		mixed := writeLocal(t, dir, "all.yaml", envelope("Widget", "kept")+"---\n"+envelope("Widget", "stale"))
+		untargeted := writeLocal(t, dir, "mixed-kinds.yaml", envelope("Gadget", "old")+"---\n"+envelope("Widget", "stale"))
+
+		recorder := pull(t, dir, true, []string{"Widget"})
+
+		require.FileExists(t, mixed)
+		require.FileExists(t, untargeted)
+		require.Equal(t, 0, recorder.Summary().EventCounts[grizzly.ResourceRemoved])
+	})
+
+	t.Run("resources of other scopes are kept", func(t *testing.T) {
+		dir := t.TempDir()
+		scoped := &scopedFakeProvider{}
+		scoped.handlers = []grizzly.Handler{newFakeHandler(scoped, "Widget", map[string]map[string]any{})}
+		scopedRegistry := grizzly.NewRegistry([]grizzly.Provider{scoped})
+		other := writeLocal(t, dir, "Widget/other.yaml", "apiVersion: grizzly.grafana.com/v1alpha1\nkind: Widget\nmetadata:\n  name: other\n  scope: b\nspec:\n  title: other\n")
+		stale := writeLocal(t, dir, "Widget/stale.yaml", envelope("Widget", "stale"))
+
+		err := grizzly.Pull(scopedRegistry, dir, grizzly.PullOptions{
+			OutputFormat: "yaml",
+			Sync:         true,
+			Parser:       grizzly.DefaultParser(scopedRegistry, nil, nil, grizzly.ParserContinueOnError(true)),
+		}, &memoryRecorder{})
+		require.NoError(t, err)
+
+		require.FileExists(t, other)
+		require.NoFileExists(t, stale)
+	})
+
+	t.Run("non-rewritable sources are never removed", func(t *testing.T) {
+		dir := t.TempDir()
+		jsonnet := writeLocal(t, dir, "main.jsonnet", `{ apiVersion: "grizzly.grafana.com/v1alpha1", kind: "Widget", metadata: { name: "stale" }, spec: { title: "stale" } }`)
+
+		pull(t, dir, true, nil)
+
+		_, err := os.Stat(jsonnet)
+		require.NoError(t, err)
+	})
+}
+
+// deletingHandler records the order resources are deleted in
+type deletingHandler struct {
+	*fakeHandler
+	deleted *[]string
+}
+
+func (h deletingHandler) Delete(resource grizzly.Resource) error {
+	*h.deleted = append(*h.deleted, resource.Ref().String())
+	return nil
+}
+
+func TestDelete(t *testing.T) {
+	var deleted []string
+	provider := &fakeProvider{}
+	provider.handlers = []grizzly.Handler{
+		deletingHandler{fakeHandler: newFakeHandler(provider, "Organization", nil), deleted: &deleted},
+		deletingHandler{fakeHandler: newFakeHandler(provider, "Folder", nil), deleted: &deleted},
+	}
+	registry := grizzly.NewRegistry([]grizzly.Provider{provider})
+
+	resource := func(kind, name string) grizzly.Resource {
+		resource, err := grizzly.NewResource(provider.APIVersion(), kind, name, map[string]any{})
+		require.NoError(t, err)
+		return resource
+	}
+	resources := grizzly.NewResources(resource("Organization", "customer"), resource("Folder", "reports"))
+
+	require.NoError(t, grizzly.Delete(registry, resources, false, &memoryRecorder{}))
+	require.Equal(t, []string{"Folder.reports", "Organization.customer"}, deleted)
+}
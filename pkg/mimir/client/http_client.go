@@ -3,12 +3,10 @@ package client
 import (
 	"bytes"
 	"crypto/tls"
-	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/grafana/grizzly/internal/httputils"
@@ -35,11 +33,17 @@ type DataGroups struct {
 }
 
 type Client struct {
-	config *config.MimirConfig
+	config     *config.MimirConfig
+	httpConfig *config.HTTPConfig
+	limiter    *httputils.RateLimiter
 }
 
-func NewHTTPClient(config *config.MimirConfig) Mimir {
-	return &Client{config: config}
+func NewHTTPClient(config *config.MimirConfig, httpConfig *config.HTTPConfig) Mimir {
+	return &Client{
+		config:     config,
+		httpConfig: httpConfig,
+		limiter:    httputils.NewRateLimiter(httpConfig),
+	}
 }
 
 func (c *Client) ListRules() (map[string][]models.PrometheusRuleGroup, error) {
@@ -90,6 +94,10 @@ func (c *Client) doRequest(method string, url string, body []byte) ([]byte, erro
 		return nil, err
 	}
 
+	if err := c.config.ResolveCredentials(); err != nil {
+		return nil, err
+	}
+
 	req.Header.Set("Content-Type", "application/yaml")
 	switch {
 	case c.config.APIKey != "":
@@ -109,6 +117,7 @@ func (c *Client) doRequest(method string, url string, body []byte) ([]byte, erro
 	if err != nil {
 		return nil, fmt.Errorf("request to load rules failed: %s", err)
 	}
+	defer res.Body.Close()
 
 	b, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -124,29 +133,13 @@ func (c *Client) doRequest(method string, url string, body []byte) ([]byte, erro
 
 func (c *Client) createHTTPClient() (*http.Client, error) {
 	tlsConfig := &tls.Config{}
-	httpClient, err := httputils.NewHTTPClient()
-	if err != nil {
-		return nil, err
-	}
-
-	httpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig}
 
 	if c.config.TLS.CAPath != "" {
-		certPool, err := x509.SystemCertPool()
+		certPool, err := httputils.LoadCertPool(c.config.TLS.CAPath)
 		if err != nil {
 			return nil, err
 		}
 
-		caCertPEM, err := os.ReadFile(c.config.TLS.CAPath)
-		if err != nil {
-			return nil, err
-		}
-
-		ok := certPool.AppendCertsFromPEM(caCertPEM)
-		if !ok {
-			return nil, fmt.Errorf("could not append ca-bundle at path %s to existing certificates", c.config.TLS.CAPath)
-		}
-
 		tlsConfig.RootCAs = certPool
 	}
 
@@ -159,9 +152,9 @@ func (c *Client) createHTTPClient() (*http.Client, error) {
 		tlsConfig.Certificates = []tls.Certificate{clientTLSCert}
 	}
 
-	httpClient.Transport = &http.Transport{
-		TLSClientConfig: tlsConfig,
-	}
-
-	return httpClient, nil
+	return httputils.NewHTTPClient(httputils.ClientOptions{
+		Config:    c.httpConfig,
+		Limiter:   c.limiter,
+		TLSConfig: tlsConfig,
+	})
 }
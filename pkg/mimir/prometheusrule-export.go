@@ -0,0 +1,75 @@
+package mimir
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"gopkg.in/yaml.v3"
+)
+
+const prometheusOperatorAPIVersion = "monitoring.coreos.com/v1"
+
+// prometheusRuleGroupFields lists the fields of rule groups PrometheusRule
+// objects support, besides their name
+var prometheusRuleGroupFields = map[string]bool{"interval": true, "limit": true, "query_offset": true, "rules": true}
+
+// ExportPrometheusRules translates rule groups into PrometheusRule objects of
+// prometheus-operator, one per Mimir namespace, holding its groups. It
+// returns a manifest per Mimir namespace, by path, and warnings about what
+// couldn't be exported. kubernetesNamespace is left out when empty.
+func ExportPrometheusRules(resources grizzly.Resources, kubernetesNamespace string) (map[string][]byte, []string, error) {
+	groupsByNamespace := map[string][]any{}
+	var warnings []string
+
+	groups := resources.OfKind(PrometheusRuleGroupKind).AsList()
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name() < groups[j].Name()
+	})
+	for _, group := range groups {
+		namespace := group.GetMetadata("namespace")
+		if namespace == "" {
+			return nil, nil, fmt.Errorf("%s %s requires a namespace metadata entry", PrometheusRuleGroupKind, group.Name())
+		}
+
+		exported := map[string]any{"name": group.Name()}
+		var unsupported []string
+		for key, value := range group.Spec() {
+			if prometheusRuleGroupFields[key] {
+				exported[key] = value
+			} else if key != "name" {
+				unsupported = append(unsupported, key)
+			}
+		}
+		if len(unsupported) != 0 {
+			sort.Strings(unsupported)
+			warnings = append(warnings, fmt.Sprintf("%s: fields %s aren't supported by PrometheusRule, left out", group.Ref(), strings.Join(unsupported, ", ")))
+		}
+		groupsByNamespace[namespace] = append(groupsByNamespace[namespace], exported)
+	}
+
+	files := map[string][]byte{}
+	sources := map[string]string{}
+	for namespace, groups := range groupsByNamespace {
+		name := grizzly.KubernetesName(namespace)
+		filename := path.Join("prometheus-rules", name+".yaml")
+		if source, found := sources[filename]; found {
+			return nil, nil, fmt.Errorf("namespaces %s and %s are both exported as PrometheusRule %s", source, namespace, name)
+		}
+		sources[filename] = namespace
+
+		content, err := yaml.Marshal(grizzly.KubernetesManifest{
+			APIVersion: prometheusOperatorAPIVersion,
+			Kind:       "PrometheusRule",
+			Metadata:   grizzly.KubernetesMetadata{Name: name, Namespace: kubernetesNamespace},
+			Spec:       map[string]any{"groups": groups},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		files[filename] = content
+	}
+	return files, warnings, nil
+}
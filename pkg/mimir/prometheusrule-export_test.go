@@ -0,0 +1,40 @@
+package mimir
+
+import (
+	"testing"
+
+	"github.com/grafana/grizzly/pkg/grizzly"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportPrometheusRules(t *testing.T) {
+	group := func(namespace, name string, spec map[string]any) grizzly.Resource {
+		resource, err := grizzly.NewResource("grizzly.grafana.com/v1alpha1", PrometheusRuleGroupKind, name, spec)
+		require.NoError(t, err)
+		resource.SetMetadata("namespace", namespace)
+		return resource
+	}
+	rules := []any{map[string]any{"alert": "PromScrapeFailed", "expr": "up != 1", "for": "1m"}}
+
+	files, warnings, err := ExportPrometheusRules(grizzly.NewResources(
+		group("grizzly_rules", "recording", map[string]any{"interval": "30s", "rules": rules}),
+		group("grizzly_rules", "alerts", map[string]any{"rules": rules, "source_tenants": []any{"team-a"}}),
+		group("other", "availability", map[string]any{"rules": rules}),
+	), "monitoring")
+	require.NoError(t, err)
+	require.Equal(t, []string{"PrometheusRuleGroup.alerts: fields source_tenants aren't supported by PrometheusRule, left out"}, warnings)
+	require.Len(t, files, 2)
+
+	var manifest map[string]any
+	require.NoError(t, yaml.Unmarshal(files["prometheus-rules/grizzly-rules.yaml"], &manifest))
+	require.Equal(t, map[string]any{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "PrometheusRule",
+		"metadata":   map[string]any{"name": "grizzly-rules", "namespace": "monitoring"},
+		"spec": map[string]any{"groups": []any{
+			map[string]any{"name": "alerts", "rules": rules},
+			map[string]any{"name": "recording", "interval": "30s", "rules": rules},
+		}},
+	}, manifest)
+}
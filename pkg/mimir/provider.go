@@ -16,8 +16,8 @@ type Provider struct {
 }
 
 // NewProvider instantiates a new Provider.
-func NewProvider(config *config.MimirConfig) *Provider {
-	clientTool := client.NewHTTPClient(config)
+func NewProvider(config *config.MimirConfig, httpConfig *config.HTTPConfig) *Provider {
+	clientTool := client.NewHTTPClient(config, httpConfig)
 	return &Provider{
 		config:     config,
 		clientTool: clientTool,
@@ -14,7 +14,9 @@ import (
 
 // Provider is a grizzly.Provider implementation for Grafana.
 type Provider struct {
-	config *config.SyntheticMonitoringConfig
+	config     *config.SyntheticMonitoringConfig
+	httpConfig *config.HTTPConfig
+	limiter    *httputils.RateLimiter
 }
 
 type ClientProvider interface {
@@ -22,9 +24,11 @@ type ClientProvider interface {
 }
 
 // NewProvider instantiates a new Provider.
-func NewProvider(config *config.SyntheticMonitoringConfig) *Provider {
+func NewProvider(config *config.SyntheticMonitoringConfig, httpConfig *config.HTTPConfig) *Provider {
 	return &Provider{
-		config: config,
+		config:     config,
+		httpConfig: httpConfig,
+		limiter:    httputils.NewRateLimiter(httpConfig),
 	}
 }
 
@@ -33,13 +37,15 @@ func (p *Provider) Validate() error {
 		p.config.URL = "https://synthetic-monitoring-api.grafana.net"
 	}
 
-	smInstallationConfigured := p.config.StackID != 0 && p.config.MetricsID != 0 && p.config.LogsID != 0 && p.config.Token != ""
+	hasToken := p.config.Token != "" || len(p.config.TokenCommand) != 0 || p.config.TokenFile != ""
+	hasAccessToken := p.config.AccessToken != "" || len(p.config.AccessTokenCommand) != 0 || p.config.AccessTokenFile != ""
+	smInstallationConfigured := p.config.StackID != 0 && p.config.MetricsID != 0 && p.config.LogsID != 0 && hasToken
 
-	if p.config.AccessToken != "" && smInstallationConfigured {
+	if hasAccessToken && smInstallationConfigured {
 		return fmt.Errorf("both access token and stack configuration (stack id, metrics id, logs id, token) are set. Only one can be used")
 	}
 
-	if p.config.AccessToken == "" && !smInstallationConfigured {
+	if !hasAccessToken && !smInstallationConfigured {
 		return fmt.Errorf("neither access token nor stack configuration (stack id, metrics id, logs id, token) are set. One must be set")
 	}
 
@@ -102,7 +108,14 @@ func (p *Provider) GetHandlers() []grizzly.Handler {
 
 // NewClient creates a new client for synthetic monitoring go client
 func (p *Provider) Client() (*smapi.Client, error) {
-	client, err := httputils.NewHTTPClient()
+	if err := p.config.ResolveCredentials(); err != nil {
+		return nil, err
+	}
+
+	client, err := httputils.NewHTTPClient(httputils.ClientOptions{
+		Config:  p.httpConfig,
+		Limiter: p.limiter,
+	})
 	if err != nil {
 		return nil, err
 	}